@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/auditor"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	// imports readers
+	_ "github.com/hellofresh/klepto/pkg/reader/mysql"
+	_ "github.com/hellofresh/klepto/pkg/reader/postgres"
+)
+
+type (
+	// AuditOptions represents the command options
+	AuditOptions struct {
+		from       string
+		to         string
+		sampleSize uint64
+		format     string
+		readOpts   connOpts
+		writeOpts  connOpts
+	}
+
+	// auditReport is the audit report attached to data access requests.
+	auditReport struct {
+		GeneratedAt time.Time
+		SampleSize  uint64
+		Passed      bool
+		Results     []*auditor.Result
+	}
+)
+
+// NewAuditCmd creates a new audit command
+func NewAuditCmd() *cobra.Command {
+	opts := new(AuditOptions)
+	cmd := &cobra.Command{
+		Use:     "audit",
+		Short:   "Audits a stolen database for personal data leaked from its source",
+		PreRunE: initConfig,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applyConnections(cmd, &opts.from, &opts.to)
+			return RunAudit(opts)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&opts.from, "from", "f", "root:root@tcp(localhost:3306)/klepto", "Database dsn the data was stolen from")
+	cmd.PersistentFlags().StringVarP(&opts.to, "to", "t", "", "Database dsn the data was stolen to")
+	cmd.PersistentFlags().Uint64Var(&opts.sampleSize, "sample", 0, "Sets the number of rows audited from each table (default audits all rows)")
+	cmd.PersistentFlags().StringVar(&opts.format, "format", "text", "Sets the report format (text or json)")
+	addReadFlags(cmd, &opts.readOpts)
+	cmd.PersistentFlags().StringVar(&opts.writeOpts.timeout, "write-timeout", "5m", "Sets the timeout for operations on the target database")
+	cmd.PersistentFlags().StringVar(&opts.writeOpts.maxConnLifetime, "write-conn-lifetime", "0", "Sets the maximum amount of time a connection may be reused on the target database")
+	cmd.PersistentFlags().IntVar(&opts.writeOpts.maxConns, "write-max-conns", 5, "Sets the maximum number of open connections to the target database")
+	cmd.PersistentFlags().IntVar(&opts.writeOpts.maxIdleConns, "write-max-idle-conns", 0, "Sets the maximum number of connections in the idle connection pool for the target database")
+
+	return cmd
+}
+
+// RunAudit is the handler for the audit command.
+func RunAudit(opts *AuditOptions) error {
+	if opts.format != "text" && opts.format != "json" {
+		return errors.Errorf("unknown report format %q", opts.format)
+	}
+
+	source := connectReader(opts.from, opts.readOpts)
+	defer source.Close()
+
+	target := connectReader(opts.to, opts.writeOpts)
+	defer target.Close()
+
+	log.Info("Auditing...")
+
+	results, err := auditor.New(source, target, globalConfig, opts.sampleSize).Audit()
+	failOnError(err, "Error while auditing")
+
+	report := &auditReport{GeneratedAt: time.Now().UTC(), SampleSize: opts.sampleSize, Passed: true, Results: results}
+	leaks := 0
+	for _, result := range results {
+		if result.Leaks > 0 {
+			report.Passed = false
+			leaks++
+		}
+	}
+
+	if opts.format == "json" {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		failOnError(e.Encode(report), "Could not encode the report")
+	} else {
+		writeReport(os.Stdout, report)
+	}
+
+	if leaks > 0 {
+		return errors.Errorf("%d columns leak source data", leaks)
+	}
+
+	log.Info("No leaks found")
+
+	return nil
+}
+
+// writeReport writes a human readable audit report.
+func writeReport(w io.Writer, report *auditReport) {
+	sample := "all rows"
+	if report.SampleSize > 0 {
+		sample = fmt.Sprintf("%d rows per table", report.SampleSize)
+	}
+
+	status := "PASSED"
+	if !report.Passed {
+		status = "FAILED"
+	}
+
+	fmt.Fprintf(w, "Anonymisation audit %s\nGenerated at: %s\nAudited: %s\n\n", status, report.GeneratedAt.Format(time.RFC3339), sample)
+
+	for _, result := range report.Results {
+		status := "ok"
+		if result.Leaks > 0 {
+			status = "LEAK"
+		}
+
+		// The values of these columns were not compared
+		skipped := result.Check == auditor.CheckNotRandomised || result.Check == auditor.CheckNoPrimaryKey || result.Check == auditor.CheckNotComparable
+		if skipped {
+			status = "skip"
+		}
+
+		fmt.Fprintf(w, "%-4s  %s.%s  %s", status, result.Table, result.Column, result.Check)
+		if result.Rule != "" {
+			fmt.Fprintf(w, " (%s)", result.Rule)
+		}
+
+		if skipped {
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintf(w, ": %d of %d values\n", result.Leaks, result.Checked)
+	}
+}
@@ -2,29 +2,51 @@ package cmd
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"strconv"
 
 	"github.com/BurntSushi/toml"
 	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/introspector"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+type (
+	// InitOptions represents the command options
+	InitOptions struct {
+		from           string
+		largeTableRows uint64
+		readOpts       connOpts
+	}
+)
+
+var bareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 // NewInitCmd creates a new init command
 func NewInitCmd() *cobra.Command {
+	opts := new(InitOptions)
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Create a fresh config file",
+		Long:  "Create a fresh config file, introspecting the database given with --from or writing a sample otherwise",
 		Run: func(cmd *cobra.Command, args []string) {
-			RunInit()
+			RunInit(opts)
 		},
 	}
 
+	cmd.PersistentFlags().StringVarP(&opts.from, "from", "f", "", "Database dsn to introspect")
+	cmd.PersistentFlags().Uint64Var(&opts.largeTableRows, "large-table-rows", 1000000, "Sets the number of rows from which only the structure of a table is copied, 0 disables it")
+	addReadFlags(cmd, &opts.readOpts)
+
 	return cmd
 }
 
 // RunInit runs the init command
-func RunInit() {
+func RunInit(opts *InitOptions) {
 	log.Infof("Initializing %s", configFileName)
 
 	_, err := os.Stat(configFileName)
@@ -32,6 +54,27 @@ func RunInit() {
 		log.Fatal("Config file already exists, refusing to overwrite")
 	}
 
+	if opts.from != "" {
+		source := connectReader(opts.from, opts.readOpts)
+		defer source.Close()
+
+		log.Info("Introspecting...")
+
+		suggestions, err := introspector.New(source, opts.largeTableRows).Introspect()
+		failOnError(err, "Error while introspecting")
+
+		f, err := os.Create(configFileName)
+		failOnError(err, "Could not create the file")
+		defer f.Close()
+
+		w := bufio.NewWriter(f)
+		writeSuggestions(w, suggestions)
+		failOnError(w.Flush(), "Could not write config")
+
+		log.Infof("Created %s with %d tables!", configFileName, len(suggestions))
+		return
+	}
+
 	f, err := os.Create(configFileName)
 	failOnError(err, "Could not create the file")
 
@@ -71,3 +114,51 @@ func RunInit() {
 
 	log.Infof("Created %s!", configFileName)
 }
+
+// writeSuggestions writes the suggested table configurations as a commented config file.
+func writeSuggestions(w io.Writer, suggestions []*introspector.Suggestion) {
+	for i, suggestion := range suggestions {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		table := suggestion.Table
+		fmt.Fprintf(w, "# %s: %d rows\n", table.Name, suggestion.Rows)
+		fmt.Fprintf(w, "[[Tables]]\n  Name = %s\n", strconv.Quote(table.Name))
+
+		if table.IgnoreData {
+			fmt.Fprintf(w, "  IgnoreData = true # %s\n", suggestion.IgnoreReason)
+			continue
+		}
+
+		if len(table.Anonymise) > 0 {
+			fmt.Fprintln(w, "  [Tables.Anonymise]")
+			for _, finding := range suggestion.Findings {
+				fmt.Fprintf(w, "    %s = %s # %s\n", tomlKey(finding.Column), strconv.Quote(finding.Rule), finding.Reason)
+			}
+		}
+
+		for _, r := range table.Relationships {
+			fmt.Fprintln(w, "  [[Tables.Relationships]]")
+			fmt.Fprintf(w, "    ReferencedTable = %s\n", strconv.Quote(r.ReferencedTable))
+			if r.ForeignKey != "" {
+				fmt.Fprintf(w, "    ForeignKey = %s\n", strconv.Quote(r.ForeignKey))
+				fmt.Fprintf(w, "    ReferencedKey = %s\n", strconv.Quote(r.ReferencedKey))
+			}
+			for _, key := range r.Keys {
+				fmt.Fprintln(w, "    [[Tables.Relationships.Keys]]")
+				fmt.Fprintf(w, "      ForeignKey = %s\n", strconv.Quote(key.ForeignKey))
+				fmt.Fprintf(w, "      ReferencedKey = %s\n", strconv.Quote(key.ReferencedKey))
+			}
+		}
+	}
+}
+
+// tomlKey quotes the key when it cannot be written as a bare key.
+func tomlKey(key string) string {
+	if bareKeyRegex.MatchString(key) {
+		return key
+	}
+
+	return strconv.Quote(key)
+}
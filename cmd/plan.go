@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/anonymiser"
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	// imports readers
+	_ "github.com/hellofresh/klepto/pkg/reader/mysql"
+	_ "github.com/hellofresh/klepto/pkg/reader/postgres"
+)
+
+const (
+	planCopy          = "copy"
+	planStructureOnly = "structure only"
+	planSkip          = "skip, not found in the source database"
+)
+
+type (
+	// PlanOptions represents the command options
+	PlanOptions struct {
+		from     string
+		count    bool
+		readOpts connOpts
+	}
+
+	// tablePlan describes how a table would be stolen.
+	tablePlan struct {
+		name   string
+		status string
+		query  string
+		args   []interface{}
+		rows   *uint64
+		// estimated is set when the rows are estimated by the query planner
+		estimated bool
+		joins     []string
+		rules     map[string]string
+		err       error
+	}
+)
+
+// NewPlanCmd creates a new plan command
+func NewPlanCmd() *cobra.Command {
+	opts := new(PlanOptions)
+	cmd := &cobra.Command{
+		Use:     "plan",
+		Aliases: []string{"validate"},
+		Short:   "Shows what a steal would do with the configuration, without copying anything",
+		PreRunE: initConfig,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applyConnections(cmd, &opts.from, nil)
+			return RunPlan(opts)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&opts.from, "from", "f", "root:root@tcp(localhost:3306)/klepto", "Database dsn to plan the steal from")
+	cmd.PersistentFlags().BoolVar(&opts.count, "count", false, "Counts the exact rows each table query would return instead of estimating them, running every query")
+	addReadFlags(cmd, &opts.readOpts)
+
+	return cmd
+}
+
+// RunPlan is the handler for the plan command.
+func RunPlan(opts *PlanOptions) error {
+	source := connectReader(opts.from, opts.readOpts)
+	defer source.Close()
+
+	problems, err := anonymiser.Validate(source, globalConfig.Tables, globalConfig.Anonymise)
+	failOnError(err, "Error while validating the anonymisation config")
+
+	plans, err := planTables(source, globalConfig, opts.count)
+	failOnError(err, "Error while planning")
+
+	writePlan(os.Stdout, plans)
+
+	for _, problem := range problems {
+		log.WithError(problem).Error("Invalid anonymisation rule")
+	}
+
+	failed := len(problems)
+	for _, plan := range plans {
+		if plan.err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d problems found in the configuration", failed)
+	}
+
+	log.Info("The configuration is valid")
+
+	return nil
+}
+
+// planTables plans the source tables followed by the configured tables that are missing in the source.
+func planTables(source reader.Reader, spec *config.Spec, count bool) ([]*tablePlan, error) {
+	tables, err := source.GetTables()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tables")
+	}
+
+	planner, canPlan := source.(reader.Planner)
+
+	var plans []*tablePlan
+	existing := make(map[string]bool, len(tables))
+	for _, tableName := range tables {
+		existing[tableName] = true
+
+		table, err := spec.Tables.FindByName(tableName)
+		if err != nil {
+			table = &config.Table{Name: tableName}
+		}
+
+		plan := &tablePlan{name: tableName, status: planCopy}
+		plans = append(plans, plan)
+
+		if table.IgnoreData {
+			plan.status = planStructureOnly
+			continue
+		}
+
+		for _, r := range table.Relationships {
+			plan.joins = append(plan.joins, describeJoin(r, tableName))
+		}
+
+		plan.rules, plan.err = anonymiser.TableRules(source, table, spec.Anonymise)
+		if plan.err != nil || !canPlan {
+			continue
+		}
+
+		opts := reader.NewReadTableOpt(table, spec)
+		plan.query, plan.args, plan.err = planner.BuildQuery(tableName, opts, spec.Matchers)
+		if plan.err != nil {
+			continue
+		}
+
+		var rows uint64
+		if count {
+			rows, err = planner.CountRows(tableName, opts, spec.Matchers)
+		} else {
+			rows, err = planner.EstimateRows(tableName, opts, spec.Matchers)
+			plan.estimated = true
+		}
+		if err != nil {
+			plan.err = err
+			continue
+		}
+		plan.rows = &rows
+	}
+
+	for _, table := range spec.Tables {
+		if !existing[table.Name] {
+			plans = append(plans, &tablePlan{name: table.Name, status: planSkip})
+		}
+	}
+
+	return plans, nil
+}
+
+// describeJoin returns a readable description of the relationship join.
+func describeJoin(r *config.Relationship, tableName string) string {
+	table := r.Table
+	if table == "" {
+		table = tableName
+	}
+
+	joined := r.ReferencedTable
+	if r.Alias != "" {
+		joined = fmt.Sprintf("%s AS %s", r.ReferencedTable, r.Alias)
+	}
+
+	var on []string
+	for _, key := range r.KeyPairs() {
+		on = append(on, fmt.Sprintf("%s.%s = %s.%s", r.Name(), key.ReferencedKey, table, key.ForeignKey))
+	}
+
+	joinType := config.InnerJoin
+	if r.JoinType != "" {
+		joinType = strings.ToUpper(r.JoinType)
+	}
+
+	return fmt.Sprintf("%s JOIN %s ON %s", joinType, joined, strings.Join(on, " AND "))
+}
+
+// writePlan writes a human readable description of the plans.
+func writePlan(w io.Writer, plans []*tablePlan) {
+	for _, plan := range plans {
+		fmt.Fprintf(w, "%s: %s\n", plan.name, plan.status)
+
+		if plan.query != "" {
+			fmt.Fprintf(w, "  query: %s\n", plan.query)
+		}
+		if len(plan.args) > 0 {
+			fmt.Fprintf(w, "  args: %v\n", plan.args)
+		}
+		if plan.rows != nil && plan.estimated {
+			fmt.Fprintf(w, "  rows: ~%d (estimate)\n", *plan.rows)
+		} else if plan.rows != nil {
+			fmt.Fprintf(w, "  rows: %d\n", *plan.rows)
+		}
+		for _, join := range plan.joins {
+			fmt.Fprintf(w, "  join: %s\n", join)
+		}
+
+		if len(plan.rules) > 0 {
+			columns := make([]string, 0, len(plan.rules))
+			for column := range plan.rules {
+				columns = append(columns, column)
+			}
+			sort.Strings(columns)
+
+			fmt.Fprintln(w, "  anonymise:")
+			for _, column := range columns {
+				fmt.Fprintf(w, "    %s = %s\n", column, plan.rules[column])
+			}
+		}
+
+		if plan.err != nil {
+			fmt.Fprintf(w, "  error: %s\n", plan.err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"os"
+	"strings"
 
 	"github.com/hellofresh/klepto/pkg/config"
 	"github.com/hellofresh/klepto/pkg/formatter"
@@ -15,6 +16,8 @@ var (
 	globalConfig   *config.Spec
 	configFile     string
 	configFileName = ".klepto.toml"
+	profile        string
+	vars           []string
 	verbose        bool
 
 	// RootCmd steals and anonymises databases
@@ -33,6 +36,8 @@ var (
 
 func init() {
 	RootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to config file (default is ./.klepto)")
+	RootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Name of the config profile to apply")
+	RootCmd.PersistentFlags().StringArrayVar(&vars, "var", nil, "Sets a matcher parameter as name=value, can be repeated")
 	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Make the operation more talkative")
 
 	RootCmd.AddCommand(NewMirrorCmd())
@@ -40,6 +45,11 @@ func init() {
 	RootCmd.AddCommand(NewVersionCmd())
 	RootCmd.AddCommand(NewUpdateCmd())
 	RootCmd.AddCommand(NewInitCmd())
+	RootCmd.AddCommand(NewScanCmd())
+	RootCmd.AddCommand(NewPlanCmd())
+	RootCmd.AddCommand(NewVerifyCmd())
+	RootCmd.AddCommand(NewAuditCmd())
+	RootCmd.AddCommand(NewSchemaCmd())
 
 	log.SetOutput(os.Stderr)
 	log.SetFormatter(&formatter.CliFormatter{})
@@ -66,13 +76,61 @@ func initConfig(c *cobra.Command, args []string) error {
 		return errors.Wrap(err, "Could not read configurations")
 	}
 
-	err = viper.Unmarshal(&globalConfig)
+	var problems []*config.Problem
+	globalConfig, problems, err = config.Load(viper.ConfigFileUsed(), profile)
 	if err != nil {
-		return errors.Wrap(err, "Could not unmarshal config file")
+		return errors.Wrap(err, "Could not load config file")
+	}
+
+	for _, problem := range problems {
+		log.Error(problem)
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("%d problems found in the config files", len(problems))
+	}
+
+	err = config.Interpolate(globalConfig)
+	if err != nil {
+		return errors.Wrap(err, "Could not interpolate config file")
+	}
+
+	return applyVars()
+}
+
+// applyVars sets the matcher parameters, the --var flags override the environment that overrides the config file.
+func applyVars() error {
+	if globalConfig.Vars == nil {
+		globalConfig.Vars = make(config.Vars)
+	}
+
+	for name, value := range config.EnvVars() {
+		globalConfig.Vars[name] = value
+	}
+
+	for _, v := range vars {
+		pair := strings.SplitN(v, "=", 2)
+		if len(pair) != 2 || pair[0] == "" {
+			return errors.Errorf("Invalid --var %q, expected name=value", v)
+		}
+
+		globalConfig.Vars[strings.ToLower(pair[0])] = pair[1]
 	}
 
 	return nil
 }
+
+// applyConnections uses the connections of the config file for the dsn flags that are not set.
+func applyConnections(cmd *cobra.Command, from *string, to *string) {
+	if !cmd.Flags().Changed("from") && globalConfig.Connections.From != "" {
+		*from = globalConfig.Connections.From
+	}
+
+	if to != nil && !cmd.Flags().Changed("to") && globalConfig.Connections.To != "" {
+		*to = globalConfig.Connections.To
+	}
+}
+
 func workingDir() string {
 	cwd, err := os.Getwd()
 	failOnError(err, "Can't find the working directory")
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/hellofresh/klepto/pkg/scanner"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	// imports readers
+	_ "github.com/hellofresh/klepto/pkg/reader/mysql"
+	_ "github.com/hellofresh/klepto/pkg/reader/postgres"
+)
+
+type (
+	// ScanOptions represents the command options
+	ScanOptions struct {
+		from       string
+		sampleSize uint64
+		readOpts   connOpts
+	}
+)
+
+// NewScanCmd creates a new scan command
+func NewScanCmd() *cobra.Command {
+	opts := new(ScanOptions)
+	cmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Scans a database for columns that look like personal data and suggests anonymisation rules",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunScan(opts)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&opts.from, "from", "f", "root:root@tcp(localhost:3306)/klepto", "Database dsn to scan")
+	cmd.PersistentFlags().Uint64Var(&opts.sampleSize, "sample", 100, "Sets the number of rows sampled from each table")
+	addReadFlags(cmd, &opts.readOpts)
+
+	return cmd
+}
+
+// RunScan is the handler for the scan command.
+func RunScan(opts *ScanOptions) error {
+	source := connectReader(opts.from, opts.readOpts)
+	defer source.Close()
+
+	log.Info("Scanning...")
+
+	findings, err := scanner.New(source, opts.sampleSize).Scan()
+	failOnError(err, "Error while scanning")
+
+	log.WithField("findings", len(findings)).Info("Done!")
+
+	writeFindings(os.Stdout, findings)
+
+	return nil
+}
+
+// writeFindings writes the findings as a ready to paste configuration snippet.
+func writeFindings(w io.Writer, findings []*scanner.Finding) {
+	table := ""
+	for _, finding := range findings {
+		if finding.Table != table {
+			if table != "" {
+				fmt.Fprintln(w)
+			}
+
+			table = finding.Table
+			fmt.Fprintf(w, "[[Tables]]\n  Name = %s\n  [Tables.Anonymise]\n", strconv.Quote(table))
+		}
+
+		fmt.Fprintf(w, "    %s = %s # %s\n", tomlKey(finding.Column), strconv.Quote(finding.Rule), finding.Reason)
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// NewSchemaCmd creates a new schema command
+func NewSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Prints the JSON Schema of the config file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSchema()
+		},
+	}
+
+	return cmd
+}
+
+// RunSchema is the handler for the schema command.
+func RunSchema() error {
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+
+	return e.Encode(config.Schema())
+}
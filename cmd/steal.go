@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hellofresh/klepto/pkg/anonymiser"
 	"github.com/hellofresh/klepto/pkg/dumper"
+	"github.com/hellofresh/klepto/pkg/incremental"
 	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -26,6 +30,11 @@ type (
 		concurrency int
 		readOpts    connOpts
 		writeOpts   connOpts
+		strict      bool
+		incremental bool
+		stateFile   string
+		schemaOnly  bool
+		dataOnly    bool
 	}
 	connOpts struct {
 		timeout         string
@@ -43,6 +52,7 @@ func NewStealCmd() *cobra.Command {
 		Short:   "Steals and anonymises databases",
 		PreRunE: initConfig,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			applyConnections(cmd, &opts.from, &opts.to)
 			return RunSteal(opts)
 		},
 	}
@@ -50,42 +60,37 @@ func NewStealCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&opts.from, "from", "f", "root:root@tcp(localhost:3306)/klepto", "Database dsn to steal from")
 	cmd.PersistentFlags().StringVarP(&opts.to, "to", "t", "os://stdout/", "Database to output to (default writes to stdOut)")
 	cmd.PersistentFlags().IntVar(&opts.concurrency, "concurrency", runtime.NumCPU(), "Sets the amount of dumps to be performed concurrently")
-	cmd.PersistentFlags().StringVar(&opts.readOpts.timeout, "read-timeout", "5m", "Sets the timeout for read operations")
+	addReadFlags(cmd, &opts.readOpts)
 	cmd.PersistentFlags().StringVar(&opts.writeOpts.timeout, "write-timeout", "30s", "Sets the timeout for write operations")
-	cmd.PersistentFlags().StringVar(&opts.readOpts.maxConnLifetime, "read-conn-lifetime", "0", "Sets the maximum amount of time a connection may be reused on the read database")
-	cmd.PersistentFlags().IntVar(&opts.readOpts.maxConns, "read-max-conns", 5, "Sets the maximum number of open connections to the read database")
-	cmd.PersistentFlags().IntVar(&opts.readOpts.maxIdleConns, "read-max-idle-conns", 0, "Sets the maximum number of connections in the idle connection pool for the read database")
 	cmd.PersistentFlags().StringVar(&opts.writeOpts.maxConnLifetime, "write-conn-lifetime", "0", "Sets the maximum amount of time a connection may be reused on the write database")
 	cmd.PersistentFlags().IntVar(&opts.writeOpts.maxConns, "write-max-conns", 5, "Sets the maximum number of open connections to the write database")
 	cmd.PersistentFlags().IntVar(&opts.writeOpts.maxIdleConns, "write-max-idle-conns", 0, "Sets the maximum number of connections in the idle connection pool for the write database")
+	cmd.PersistentFlags().BoolVar(&opts.strict, "strict", false, "Refuses to steal when a column of a copied table is neither anonymised nor explicitly kept")
+	cmd.PersistentFlags().BoolVar(&opts.incremental, "incremental", false, "Only copies the rows of the incremental tables changed since the last steal into the existing target tables")
+	cmd.PersistentFlags().StringVar(&opts.stateFile, "state", ".klepto.state.json", "Path to the file storing the watermarks of the incremental tables")
+	cmd.PersistentFlags().BoolVar(&opts.schemaOnly, "schema-only", false, "Only copies the structure, without any data")
+	cmd.PersistentFlags().BoolVar(&opts.dataOnly, "data-only", false, "Only copies the data into the existing target tables, mapping their columns by name")
 	return cmd
 }
 
 // RunSteal is the handler for the rootCmd.
 func RunSteal(opts *StealOptions) (err error) {
-	readTimeout, err := time.ParseDuration(opts.readOpts.timeout)
-	failOnError(err, "Failed to parse read timeout duration")
-
 	writeTimeout, err := time.ParseDuration(opts.readOpts.timeout)
 	failOnError(err, "Failed to parse write timeout duration")
 
-	readMaxConnLifetime, err := time.ParseDuration(opts.readOpts.maxConnLifetime)
-	failOnError(err, "Failed to parse the timeout duration")
-
 	writeMaxConnLifetime, err := time.ParseDuration(opts.writeOpts.maxConnLifetime)
 	failOnError(err, "Failed to parse the timeout duration")
 
-	source, err := reader.Connect(reader.ConnOpts{
-		DSN:             opts.from,
-		Timeout:         readTimeout,
-		MaxConnLifetime: readMaxConnLifetime,
-		MaxConns:        opts.readOpts.maxConns,
-		MaxIdleConns:    opts.readOpts.maxIdleConns,
-	})
-	failOnError(err, "Error connecting to reader")
+	source := connectReader(opts.from, opts.readOpts)
 	defer source.Close()
 
-	source = anonymiser.NewAnonymiser(source, globalConfig.Tables)
+	failOnError(validateRules(source), "Invalid anonymisation config")
+
+	if opts.strict {
+		failOnError(checkClassified(source), "Strict mode")
+	}
+
+	source = anonymiser.NewAnonymiser(source, globalConfig.Tables, globalConfig.Anonymise)
 	target, err := dumper.NewDumper(dumper.ConnOpts{
 		DSN:             opts.to,
 		Timeout:         writeTimeout,
@@ -101,10 +106,106 @@ func RunSteal(opts *StealOptions) (err error) {
 	done := make(chan struct{})
 	defer close(done)
 	start := time.Now()
-	failOnError(target.Dump(done, globalConfig, opts.concurrency), "Error while dumping")
+	dumpOpts := dumper.DumpOpts{
+		Concurrency: opts.concurrency,
+		Incremental: opts.incremental,
+		SchemaOnly:  opts.schemaOnly,
+		DataOnly:    opts.dataOnly,
+	}
+	failOnError(dumpOpts.Validate(), "Invalid steal options")
+
+	if opts.incremental || hasIncrementalTables() {
+		dumpOpts.State, err = incremental.Load(opts.stateFile)
+		failOnError(err, "Error loading the incremental state")
+	}
+
+	failOnError(target.Dump(done, globalConfig, dumpOpts), "Error while dumping")
 
 	<-done
 	log.WithField("total_time", time.Since(start)).Info("Done!")
 
 	return nil
 }
+
+// hasIncrementalTables checks if the watermark of a table has to be tracked.
+func hasIncrementalTables() bool {
+	for _, table := range globalConfig.Tables {
+		if table.Incremental.Column != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateRules fails when an anonymisation rule of the configuration cannot be applied, logging all problems found.
+func validateRules(source reader.Reader) error {
+	problems, err := anonymiser.Validate(source, globalConfig.Tables, globalConfig.Anonymise)
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range problems {
+		log.WithError(problem).Error("Invalid anonymisation rule")
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("%d problems found in the anonymisation rules", len(problems))
+	}
+
+	return nil
+}
+
+// addReadFlags adds the flags to configure the connection to the read database.
+func addReadFlags(cmd *cobra.Command, opts *connOpts) {
+	cmd.PersistentFlags().StringVar(&opts.timeout, "read-timeout", "5m", "Sets the timeout for read operations")
+	cmd.PersistentFlags().StringVar(&opts.maxConnLifetime, "read-conn-lifetime", "0", "Sets the maximum amount of time a connection may be reused on the read database")
+	cmd.PersistentFlags().IntVar(&opts.maxConns, "read-max-conns", 5, "Sets the maximum number of open connections to the read database")
+	cmd.PersistentFlags().IntVar(&opts.maxIdleConns, "read-max-idle-conns", 0, "Sets the maximum number of connections in the idle connection pool for the read database")
+}
+
+// connectReader connects to the read database, failing on error.
+func connectReader(dsn string, opts connOpts) reader.Reader {
+	readTimeout, err := time.ParseDuration(opts.timeout)
+	failOnError(err, "Failed to parse read timeout duration")
+
+	readMaxConnLifetime, err := time.ParseDuration(opts.maxConnLifetime)
+	failOnError(err, "Failed to parse the timeout duration")
+
+	source, err := reader.Connect(reader.ConnOpts{
+		DSN:             dsn,
+		Timeout:         readTimeout,
+		MaxConnLifetime: readMaxConnLifetime,
+		MaxConns:        opts.maxConns,
+		MaxIdleConns:    opts.maxIdleConns,
+	})
+	failOnError(err, "Error connecting to reader")
+
+	return source
+}
+
+// checkClassified fails when a column of a copied table is not classified by the configuration.
+func checkClassified(source reader.Reader) error {
+	unclassified, err := anonymiser.Unclassified(source, globalConfig.Tables, globalConfig.Anonymise)
+	if err != nil {
+		return err
+	}
+
+	if len(unclassified) == 0 {
+		return nil
+	}
+
+	tables := make([]string, 0, len(unclassified))
+	for table := range unclassified {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		log.WithField("table", table).
+			WithField("columns", strings.Join(unclassified[table], ", ")).
+			Error("Unclassified columns, anonymise them, keep them or ignore the table data")
+	}
+
+	return errors.Errorf("%d tables have unclassified columns", len(unclassified))
+}
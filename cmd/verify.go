@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hellofresh/klepto/pkg/verifier"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	// imports readers
+	_ "github.com/hellofresh/klepto/pkg/reader/mysql"
+	_ "github.com/hellofresh/klepto/pkg/reader/postgres"
+)
+
+type (
+	// VerifyOptions represents the command options
+	VerifyOptions struct {
+		from      string
+		to        string
+		readOpts  connOpts
+		writeOpts connOpts
+	}
+)
+
+// NewVerifyCmd creates a new verify command
+func NewVerifyCmd() *cobra.Command {
+	opts := new(VerifyOptions)
+	cmd := &cobra.Command{
+		Use:     "verify",
+		Short:   "Verifies that a stolen database holds the data selected from its source",
+		PreRunE: initConfig,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applyConnections(cmd, &opts.from, &opts.to)
+			return RunVerify(opts)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&opts.from, "from", "f", "root:root@tcp(localhost:3306)/klepto", "Database dsn the data was stolen from")
+	cmd.PersistentFlags().StringVarP(&opts.to, "to", "t", "", "Database dsn the data was stolen to")
+	addReadFlags(cmd, &opts.readOpts)
+	cmd.PersistentFlags().StringVar(&opts.writeOpts.timeout, "write-timeout", "5m", "Sets the timeout for operations on the target database")
+	cmd.PersistentFlags().StringVar(&opts.writeOpts.maxConnLifetime, "write-conn-lifetime", "0", "Sets the maximum amount of time a connection may be reused on the target database")
+	cmd.PersistentFlags().IntVar(&opts.writeOpts.maxConns, "write-max-conns", 5, "Sets the maximum number of open connections to the target database")
+	cmd.PersistentFlags().IntVar(&opts.writeOpts.maxIdleConns, "write-max-idle-conns", 0, "Sets the maximum number of connections in the idle connection pool for the target database")
+
+	return cmd
+}
+
+// RunVerify is the handler for the verify command.
+func RunVerify(opts *VerifyOptions) error {
+	source := connectReader(opts.from, opts.readOpts)
+	defer source.Close()
+
+	target := connectReader(opts.to, opts.writeOpts)
+	defer target.Close()
+
+	log.Info("Verifying...")
+
+	results, err := verifier.New(source, target, globalConfig).Verify()
+	failOnError(err, "Error while verifying")
+
+	mismatches := writeResults(os.Stdout, results)
+	if mismatches > 0 {
+		return errors.Errorf("%d tables do not match their source", mismatches)
+	}
+
+	log.Info("All tables match their source")
+
+	return nil
+}
+
+// writeResults writes the verification of every table and returns the number of tables that don't match.
+func writeResults(w io.Writer, results []*verifier.Result) int {
+	mismatches := 0
+	for _, result := range results {
+		status := "ok"
+		if result.Unverifiable != "" {
+			status = "skip, " + result.Unverifiable
+		} else if !result.Matches() {
+			status = "MISMATCH"
+			mismatches++
+		}
+
+		fmt.Fprintf(w, "%s: %s\n  rows: %d source, %d target\n", result.Table, status, result.SourceRows, result.TargetRows)
+		if result.Checksummed {
+			fmt.Fprintf(w, "  checksum: %016x source, %016x target\n", result.SourceChecksum, result.TargetChecksum)
+		}
+	}
+
+	return mismatches
+}
@@ -21,22 +21,26 @@ const (
 	// literalPrefix defines the constant we use to prefix literals
 	literalPrefix     = "literal:"
 	conditionalPrefix = "cond:"
-	email             = "EmailAddress"
-	username          = "UserName"
-	password          = "Password"
+	// KeepRule marks a column to be copied as-is
+	KeepRule = "keep"
+	email    = "EmailAddress"
+	username = "UserName"
+	password = "Password"
 )
 
 type (
 	anonymiser struct {
 		reader.Reader
 		tables        config.Tables
+		rules         config.Rules
 		compiledRules map[string]*vm.Program
 	}
 )
 
-// NewAnonymiser returns a new anonymiser reader.
-func NewAnonymiser(source reader.Reader, tables config.Tables) reader.Reader {
-	return &anonymiser{source, tables, map[string]*vm.Program{}}
+// NewAnonymiser returns a new anonymiser reader,
+// rules are applied to the columns of every table unless the table configures its own.
+func NewAnonymiser(source reader.Reader, tables config.Tables, rules config.Rules) reader.Reader {
+	return &anonymiser{source, tables, rules, map[string]*vm.Program{}}
 }
 
 // ReadTable decorates reader.ReadTable method for anonymising rows published from the reader.Reader
@@ -46,33 +50,69 @@ func (a *anonymiser) ReadTable(tableName string, rowChan chan<- database.Row, op
 	table, err := a.tables.FindByName(tableName)
 	if err != nil {
 		logger.WithError(err).Debug("the table is not configured to be anonymised")
-		return a.Reader.ReadTable(tableName, rowChan, opts, matchers)
+		table = &config.Table{Name: tableName}
 	}
 
-	if len(table.Anonymise) == 0 {
+	rules, err := TableRules(a.Reader, table, a.rules)
+	if err != nil {
+		close(rowChan)
+		return errors.Wrap(err, "anonymiser: failed to get rules")
+	}
+
+	if len(rules) == 0 {
 		logger.Debug("Skipping anonymiser")
 		return a.Reader.ReadTable(tableName, rowChan, opts, matchers)
 	}
 
-	// Compile conditional anonymisation rules
-	for column, fakerType := range table.Anonymise {
-		if strings.HasPrefix(fakerType, conditionalPrefix) {
-			program, err := expr.Compile(strings.TrimPrefix(fakerType, conditionalPrefix))
+	columnTypes, err := a.columnTypes(tableName)
+	if err != nil {
+		close(rowChan)
+		return errors.Wrap(err, "anonymiser: failed to get column types")
+	}
+
+	// Compile conditional anonymisation rules, parse transformation rules and check fakers against their column types
+	transformers := make(map[string]transformer)
+	for column, fakerType := range rules {
+		if fakerType == KeepRule || strings.HasPrefix(fakerType, literalPrefix) {
+			continue
+		}
 
-			if err != nil {
-				logger.WithError(err).Error("Conditional rule compilation failed")
-				continue
+		transform, ok, err := parseTransformer(fakerType)
+		if err != nil {
+			close(rowChan)
+			return errors.Wrapf(err, "anonymiser: invalid rule for %s", RuleKey(tableName, column))
+		}
+		if ok {
+			if key, isShift := shiftKey(fakerType); isShift && columnTypes[key] == nil {
+				close(rowChan)
+				return errors.Errorf("anonymiser: shift key column %s of %s does not exist", key, RuleKey(tableName, column))
 			}
+			transformers[column] = transform
+			continue
+		}
 
-			ruleKey := RuleKey(tableName, column)
-			a.compiledRules[ruleKey] = program
+		if !strings.HasPrefix(fakerType, conditionalPrefix) {
+			if _, err := AnonymiseColumn(fakerType, columnTypes[column]); err != nil {
+				close(rowChan)
+				return errors.Wrapf(err, "anonymiser: cannot anonymise %s", RuleKey(tableName, column))
+			}
+			continue
+		}
+
+		program, err := compileCondition(fakerType)
+		if err != nil {
+			close(rowChan)
+			return errors.Wrapf(err, "anonymiser: invalid rule for %s", RuleKey(tableName, column))
 		}
+
+		ruleKey := RuleKey(tableName, column)
+		a.compiledRules[ruleKey] = program
 	}
 
 	// Create read/write chanel
 	rawChan := make(chan database.Row)
 
-	go func(rowChan chan<- database.Row, rawChan chan database.Row, table *config.Table) {
+	go func(rowChan chan<- database.Row, rawChan chan database.Row, rules map[string]string) {
 		for {
 			row, more := <-rawChan
 			if !more {
@@ -80,43 +120,48 @@ func (a *anonymiser) ReadTable(tableName string, rowChan chan<- database.Row, op
 				return
 			}
 
-			for column, fakerType := range table.Anonymise {
+			// Transformations are derived from the original values, regardless of the order columns are anonymised
+			var original database.Row
+			if len(transformers) > 0 {
+				original = make(database.Row, len(row))
+				for column, value := range row {
+					original[column] = value
+				}
+			}
+
+			for column, fakerType := range rules {
+				if fakerType == KeepRule {
+					continue
+				}
+
 				if strings.HasPrefix(fakerType, literalPrefix) {
 					row[column] = strings.TrimPrefix(fakerType, literalPrefix)
 					continue
 				}
 
-				if strings.HasPrefix(fakerType, conditionalPrefix) {
-
-					env := map[string]interface{}{
-						"row":    row,
-						"column": row[column],
-						"Value": func(row database.Row, columnName string) string {
-							columnValue := row[columnName]
-
-							if columnValue == nil {
-								return ""
-							}
+				if transform, ok := transformers[column]; ok {
+					value, err := transform(original, original[column])
+					if err != nil {
+						logger.WithError(err).WithField("column", column).Error("Transformation failed")
+						continue
+					}
 
-							bytes := columnValue.([]uint8)
+					row[column] = value
+					continue
+				}
 
-							return string(bytes)
-						},
-						"Anon": func(fakerType string) *option.Option {
-							return option.Some(Anonymise(fakerType))
-						},
-						"Skip": func() *option.Option {
+				if strings.HasPrefix(fakerType, conditionalPrefix) {
+					env := conditionEnv(row, row[column], func(fakerType string) *option.Option {
+						value, err := AnonymiseColumn(fakerType, columnTypes[column])
+						if err != nil {
+							logger.WithError(err).Error("Anonymisation failed")
 							return option.None()
-						},
-						"IsNil": func(row database.Row, columnName string) bool {
-							return row[columnName] == nil
-						},
-						"Literal": func(str string) *option.Option {
-							return option.Some(str)
-						},
-					}
+						}
 
-					ruleKey := RuleKey(table.Name, column)
+						return option.Some(value)
+					})
+
+					ruleKey := RuleKey(tableName, column)
 					output, err := expr.Run(a.compiledRules[ruleKey], env)
 					if err != nil {
 						logger.WithError(err).Error("Eval rule runtime error")
@@ -131,12 +176,18 @@ func (a *anonymiser) ReadTable(tableName string, rowChan chan<- database.Row, op
 					continue
 				}
 
-				row[column] = Anonymise(fakerType)
+				value, err := AnonymiseColumn(fakerType, columnTypes[column])
+				if err != nil {
+					logger.WithError(err).Error("Anonymisation failed")
+					continue
+				}
+
+				row[column] = value
 			}
 
 			rowChan <- row
 		}
-	}(rowChan, rawChan, table)
+	}(rowChan, rawChan, rules)
 
 	if err := a.Reader.ReadTable(tableName, rawChan, opts, matchers); err != nil {
 		return errors.Wrap(err, "anonymiser: error while reading table")
@@ -145,30 +196,104 @@ func (a *anonymiser) ReadTable(tableName string, rowChan chan<- database.Row, op
 	return nil
 }
 
+// conditionEnv returns the environment conditional rules are evaluated with.
+func conditionEnv(row database.Row, value interface{}, anon func(string) *option.Option) map[string]interface{} {
+	return map[string]interface{}{
+		"row":    row,
+		"column": value,
+		"Value": func(row database.Row, columnName string) string {
+			columnValue := row[columnName]
+
+			if columnValue == nil {
+				return ""
+			}
+
+			bytes := columnValue.([]uint8)
+
+			return string(bytes)
+		},
+		"Anon": anon,
+		"Skip": func() *option.Option {
+			return option.None()
+		},
+		"IsNil": func(row database.Row, columnName string) bool {
+			return row[columnName] == nil
+		},
+		"Literal": func(str string) *option.Option {
+			return option.Some(str)
+		},
+	}
+}
+
+// compileCondition compiles a conditional rule against the types of its environment.
+func compileCondition(rule string) (*vm.Program, error) {
+	env := conditionEnv(database.Row{}, nil, func(string) *option.Option { return option.None() })
+
+	program, err := expr.Compile(strings.TrimPrefix(rule, conditionalPrefix), expr.Env(env))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid condition")
+	}
+
+	return program, nil
+}
+
 // Anonymise generates a fake value
 func Anonymise(fakerType string) string {
-	var value string
+	value, err := generate(fakerType, nil)
+	if err != nil {
+		return ""
+	}
 
-	for name, faker := range Functions {
-		if fakerType != name {
-			continue
-		}
+	return toString(value)
+}
 
-		switch name {
-		case email, username:
-			b := make([]byte, 2)
-			rand.Read(b)
-			value = fmt.Sprintf(
-				"%s.%s",
-				faker.Call([]reflect.Value{})[0].String(),
-				hex.EncodeToString(b),
-			)
-		default:
-			value = faker.Call([]reflect.Value{})[0].String()
-		}
+func callFaker(name string, faker reflect.Value) interface{} {
+	value := faker.Call([]reflect.Value{})[0]
+
+	switch name {
+	case email, username:
+		b := make([]byte, 2)
+		rand.Read(b)
+		return fmt.Sprintf("%s.%s", value.String(), hex.EncodeToString(b))
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	default:
+		return value.String()
+	}
+}
+
+func (a *anonymiser) columnTypes(tableName string) (map[string]*database.ColumnType, error) {
+	columnTypes, err := a.Reader.GetColumnTypes(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]*database.ColumnType, len(columnTypes))
+	for _, columnType := range columnTypes {
+		types[columnType.Name] = columnType
+	}
+
+	return types, nil
+}
+
+// TableRules returns the anonymisation rules of the table columns,
+// merging the global rules with the ones of the table.
+func TableRules(rdr reader.Reader, table *config.Table, rules config.Rules) (map[string]string, error) {
+	if len(rules) == 0 {
+		return table.Anonymise, nil
+	}
+
+	columns, err := rdr.GetColumns(table.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get columns of %s", table.Name)
 	}
 
-	return value
+	return rules.Merge(table, columns), nil
 }
 
 // RuleKey generates a key for storing VM program of specific table's column.
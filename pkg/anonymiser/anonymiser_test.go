@@ -43,6 +43,13 @@ func TestReadTable(t *testing.T) {
 			config:   config.Tables{{Name: "test", Anonymise: map[string]string{"column_test": "FirstName"}}},
 			matchers: make(config.Matchers),
 		},
+		{
+			scenario: "when the rows are observed",
+			function: testWhenRowsAreObserved,
+			opts:     reader.ReadTableOpt{},
+			config:   config.Tables{{Name: "test", Anonymise: map[string]string{"column_test": "FirstName"}}},
+			matchers: make(config.Matchers),
+		},
 		{
 			scenario: "when column is anonymised with literal",
 			function: testWhenColumnIsAnonymisedWithLiteral,
@@ -60,7 +67,7 @@ func TestReadTable(t *testing.T) {
 }
 
 func testWhenAnonymiserIsNotInitialized(t *testing.T, opts reader.ReadTableOpt, tables config.Tables, matchers config.Matchers) {
-	anonymiser := NewAnonymiser(&mockReader{}, tables)
+	anonymiser := NewAnonymiser(&mockReader{}, tables, nil)
 
 	rowChan := make(chan database.Row, 1)
 	defer close(rowChan)
@@ -70,7 +77,7 @@ func testWhenAnonymiserIsNotInitialized(t *testing.T, opts reader.ReadTableOpt,
 }
 
 func testWhenTableIsNotSetInConfig(t *testing.T, opts reader.ReadTableOpt, tables config.Tables, matchers config.Matchers) {
-	anonymiser := NewAnonymiser(&mockReader{}, tables)
+	anonymiser := NewAnonymiser(&mockReader{}, tables, nil)
 
 	rowChan := make(chan database.Row, 1)
 	defer close(rowChan)
@@ -80,7 +87,7 @@ func testWhenTableIsNotSetInConfig(t *testing.T, opts reader.ReadTableOpt, table
 }
 
 func testWhenColumnIsAnonymised(t *testing.T, opts reader.ReadTableOpt, tables config.Tables, matchers config.Matchers) {
-	anonymiser := NewAnonymiser(&mockReader{}, tables)
+	anonymiser := NewAnonymiser(&mockReader{}, tables, nil)
 
 	rowChan := make(chan database.Row)
 	defer close(rowChan)
@@ -95,8 +102,25 @@ func testWhenColumnIsAnonymised(t *testing.T, opts reader.ReadTableOpt, tables c
 	}
 }
 
+func testWhenRowsAreObserved(t *testing.T, opts reader.ReadTableOpt, tables config.Tables, matchers config.Matchers) {
+	anonymiser := NewAnonymiser(&mockReader{}, tables, nil)
+
+	var observed interface{}
+	opts.OnRow = func(row database.Row) { observed = row["column_test"] }
+
+	rowChan := make(chan database.Row)
+	defer close(rowChan)
+
+	err := anonymiser.ReadTable("test", rowChan, opts, matchers)
+	require.NoError(t, err)
+
+	row := <-rowChan
+	assert.NotEqual(t, "to_be_anonimised", row["column_test"])
+	assert.Equal(t, "to_be_anonimised", observed)
+}
+
 func testWhenColumnIsAnonymisedWithLiteral(t *testing.T, opts reader.ReadTableOpt, tables config.Tables, matchers config.Matchers) {
-	anonymiser := NewAnonymiser(&mockReader{}, tables)
+	anonymiser := NewAnonymiser(&mockReader{}, tables, nil)
 
 	rowChan := make(chan database.Row)
 	defer close(rowChan)
@@ -113,17 +137,196 @@ func testWhenColumnIsAnonymisedWithLiteral(t *testing.T, opts reader.ReadTableOp
 
 type mockReader struct{}
 
-func (m *mockReader) GetTables() ([]string, error)        { return []string{"table_test"}, nil }
-func (m *mockReader) GetStructure() (string, error)       { return "", nil }
-func (m *mockReader) GetColumns(string) ([]string, error) { return []string{"column_test"}, nil }
-func (m *mockReader) GetPreamble() (string, error)        { return "", nil }
-func (m *mockReader) Close() error                        { return nil }
+func (m *mockReader) GetDatabaseName() (string, error)                { return "test", nil }
+func (m *mockReader) GetTables() ([]string, error)                    { return []string{"table_test"}, nil }
+func (m *mockReader) GetStructure() (string, error)                   { return "", nil }
+func (m *mockReader) GetViewDefinitions(*config.Spec) (string, error) { return "", nil }
+func (m *mockReader) GetColumns(string) ([]string, error)             { return []string{"column_test"}, nil }
+func (m *mockReader) GetPreamble() (string, error)                    { return "", nil }
+func (m *mockReader) GetSchema() (*database.Schema, error)            { return nil, nil }
+func (m *mockReader) Dialect() string                                 { return "test" }
+func (m *mockReader) Close() error                                    { return nil }
+func (m *mockReader) GetStructureSections() (*database.Structure, error) {
+	return &database.Structure{}, nil
+}
+func (m *mockReader) GetColumnTypes(string) ([]*database.ColumnType, error) {
+	return []*database.ColumnType{{Name: "column_test", DatabaseType: "VARCHAR", Length: 255}}, nil
+}
 func (m *mockReader) FormatColumn(tbl string, col string) string {
 	return fmt.Sprintf("%s.%s", strconv.Quote(tbl), strconv.Quote(col))
 }
 func (m *mockReader) ReadTable(tableName string, rowChan chan<- database.Row, opts reader.ReadTableOpt, matchers config.Matchers) error {
 	row := make(database.Row)
 	row["column_test"] = "to_be_anonimised"
+	if opts.OnRow != nil {
+		opts.OnRow(row)
+	}
 	rowChan <- row
 	return nil
 }
+
+func TestReadTableWithMissingShiftKey(t *testing.T) {
+	t.Parallel()
+
+	tables := config.Tables{{Name: "test", Anonymise: map[string]string{"column_test": "shift:30"}}}
+	anonymiser := NewAnonymiser(&mockReader{}, tables, nil)
+
+	err := anonymiser.ReadTable("test", make(chan database.Row, 1), reader.ReadTableOpt{}, nil)
+	assert.EqualError(t, err, "anonymiser: shift key column id of test.column_test does not exist")
+}
+
+func TestUnclassified(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		tables   config.Tables
+		expected map[string][]string
+	}{
+		{
+			scenario: "when table is not configured",
+			tables:   config.Tables{},
+			expected: map[string][]string{"table_test": {"column_test"}},
+		},
+		{
+			scenario: "when column is anonymised",
+			tables:   config.Tables{{Name: "table_test", Anonymise: map[string]string{"column_test": "FirstName"}}},
+			expected: map[string][]string{},
+		},
+		{
+			scenario: "when column is kept",
+			tables:   config.Tables{{Name: "table_test", Anonymise: map[string]string{"column_test": "keep"}}},
+			expected: map[string][]string{},
+		},
+		{
+			scenario: "when table data is ignored",
+			tables:   config.Tables{{Name: "table_test", IgnoreData: true}},
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			unclassified, err := Unclassified(&mockReader{}, test.tables, nil)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, unclassified)
+		})
+	}
+}
+
+func TestReadTableWithGlobalRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		tables   config.Tables
+		expected string
+	}{
+		{
+			scenario: "when table is not set in the config",
+			tables:   config.Tables{},
+			expected: "Global",
+		},
+		{
+			scenario: "when table overrides the global rule",
+			tables:   config.Tables{{Name: "test", Anonymise: map[string]string{"column_test": "literal:Table"}}},
+			expected: "Table",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			anonymiser := NewAnonymiser(&mockReader{}, test.tables, config.Rules{"*_test": "literal:Global"})
+
+			rowChan := make(chan database.Row, 1)
+			err := anonymiser.ReadTable("test", rowChan, reader.ReadTableOpt{}, nil)
+			require.NoError(t, err)
+
+			row := <-rowChan
+			assert.Equal(t, test.expected, row["column_test"])
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		tables   config.Tables
+		rules    config.Rules
+		problems int
+	}{
+		{
+			scenario: "when rules are valid",
+			tables:   config.Tables{{Name: "table_test", Anonymise: map[string]string{"column_test": "EmailAddress"}}},
+			rules:    config.Rules{"*email*": "keep"},
+		},
+		{
+			scenario: "when faker is unknown",
+			tables:   config.Tables{{Name: "table_test", Anonymise: map[string]string{"column_test": "EmailAdress"}}},
+			problems: 1,
+		},
+		{
+			scenario: "when table and column do not exist",
+			tables: config.Tables{
+				{Name: "other_table", Anonymise: map[string]string{"column_test": "EmailAddress"}},
+				{Name: "table_test", Anonymise: map[string]string{"other_column": "EmailAddress"}},
+			},
+			problems: 2,
+		},
+		{
+			scenario: "when the shift key column does not exist",
+			tables:   config.Tables{{Name: "table_test", Anonymise: map[string]string{"column_test": "shift:30"}}},
+			problems: 1,
+		},
+		{
+			scenario: "when the shift key column exists",
+			tables:   config.Tables{{Name: "table_test", Anonymise: map[string]string{"column_test": "shift:30:column_test"}}},
+		},
+		{
+			scenario: "when the global shift key column does not exist",
+			rules:    config.Rules{"*_test": "shift:30:user_id"},
+			problems: 1,
+		},
+		{
+			scenario: "when global rules are invalid",
+			rules:    config.Rules{"/[/": "EmailAddress", "*phone*": "noise:abc"},
+			problems: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			problems, err := Validate(&mockReader{}, test.tables, test.rules)
+			require.NoError(t, err)
+			assert.Len(t, problems, test.problems)
+		})
+	}
+}
+
+func TestHasFewValues(t *testing.T) {
+	t.Parallel()
+
+	varchar := &database.ColumnType{Name: "value", DatabaseType: "VARCHAR", Length: 255}
+	tinyint := &database.ColumnType{Name: "value", DatabaseType: "TINYINT"}
+
+	assert.True(t, HasFewValues("Integer:1:10", varchar))
+	assert.True(t, HasFewValues("Gender", varchar))
+	assert.True(t, HasFewValues("Integer", tinyint))
+	assert.False(t, HasFewValues("EmailAddress", varchar))
+	assert.False(t, HasFewValues("UUID", varchar))
+	assert.False(t, HasFewValues("Integer", varchar))
+}
+
+func TestIsFaker(t *testing.T) {
+	t.Parallel()
+
+	for _, rule := range []string{"EmailAddress", "UUID", "Integer:1:10", "Date"} {
+		assert.True(t, IsFaker(rule), rule)
+	}
+
+	for _, rule := range []string{KeepRule, "literal:x", "cond:Skip()", "bucket:10", "truncate:3", "shift:30", "noise:5%", "mask:last=4", "Unknown"} {
+		assert.False(t, IsFaker(rule), rule)
+	}
+}
@@ -0,0 +1,152 @@
+package anonymiser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maskPrefix hides a value while keeping its shape e.g. mask:last=4 or mask:first=1,until=@
+	maskPrefix = "mask:"
+	// regexPrefix replaces the matches of a pattern with a faker or a literal e.g. regex:\d{3}-\d{4}=>Phone
+	regexPrefix = "regex:"
+
+	defaultMaskChar  = '*'
+	regexReplacement = "=>"
+)
+
+type maskSettings struct {
+	// first is the number of leading letters and digits to keep.
+	first int
+	// last is the number of trailing letters and digits to keep.
+	last int
+	// char is the character used to mask.
+	char rune
+	// until limits masking to the part of the value before the first occurrence of it.
+	until string
+}
+
+func newMaskTransformer(args string) (transformer, error) {
+	settings := maskSettings{char: defaultMaskChar}
+
+	for _, setting := range strings.Split(args, ",") {
+		if setting == "" {
+			continue
+		}
+
+		kv := strings.SplitN(setting, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("invalid mask setting %q, expected key=value", setting)
+		}
+
+		var err error
+		switch key, value := strings.TrimSpace(kv[0]), kv[1]; key {
+		case "first":
+			settings.first, err = strconv.Atoi(value)
+		case "last":
+			settings.last, err = strconv.Atoi(value)
+		case "char":
+			if len([]rune(value)) != 1 {
+				err = errors.New("expected a single character")
+			} else {
+				settings.char = []rune(value)[0]
+			}
+		case "until":
+			settings.until = value
+		default:
+			err = errors.New("unknown setting")
+		}
+
+		if err != nil || settings.first < 0 || settings.last < 0 {
+			return nil, errors.Errorf("invalid mask setting %q", setting)
+		}
+	}
+
+	return func(row database.Row, value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case nil:
+			return nil, nil
+		case []byte:
+			return mask(string(v), settings), nil
+		default:
+			return mask(toString(v), settings), nil
+		}
+	}, nil
+}
+
+// mask replaces the letters and digits of the value with the mask character,
+// keeping separators and the configured leading and trailing characters.
+func mask(str string, settings maskSettings) string {
+	head, tail := str, ""
+	if settings.until != "" {
+		if i := strings.Index(str, settings.until); i >= 0 {
+			head, tail = str[:i], str[i:]
+		}
+	}
+
+	runes := []rune(head)
+
+	maskable := 0
+	for _, r := range runes {
+		if isMaskable(r) {
+			maskable++
+		}
+	}
+
+	position := 0
+	for i, r := range runes {
+		if !isMaskable(r) {
+			continue
+		}
+
+		if position >= settings.first && position < maskable-settings.last {
+			runes[i] = settings.char
+		}
+		position++
+	}
+
+	return string(runes) + tail
+}
+
+func isMaskable(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func newRegexTransformer(args string) (transformer, error) {
+	i := strings.LastIndex(args, regexReplacement)
+	if i < 0 {
+		return nil, errors.Errorf("invalid regex rule %q, expected pattern%sreplacement", args, regexReplacement)
+	}
+
+	pattern, replacement := args[:i], args[i+len(regexReplacement):]
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid regex pattern")
+	}
+
+	replace := func(string) string { return strings.TrimPrefix(replacement, literalPrefix) }
+	if !strings.HasPrefix(replacement, literalPrefix) {
+		if _, err := generate(replacement, nil); err != nil {
+			return nil, errors.Wrap(err, "invalid regex replacement")
+		}
+
+		replace = func(string) string { return Anonymise(replacement) }
+	}
+
+	return func(row database.Row, value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case nil:
+			return nil, nil
+		case []byte:
+			return re.ReplaceAllStringFunc(string(v), replace), nil
+		default:
+			return re.ReplaceAllStringFunc(toString(v), replace), nil
+		}
+	}, nil
+}
@@ -0,0 +1,84 @@
+package anonymiser
+
+import (
+	"testing"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskAndRegexTransformers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		rule     string
+		value    interface{}
+		assert   func(*testing.T, interface{})
+	}{
+		{
+			scenario: "when card number is masked",
+			rule:     "mask:last=4",
+			value:    []byte("4111-1111-1111-1234"),
+			assert: func(t *testing.T, value interface{}) {
+				assert.Equal(t, "****-****-****-1234", value)
+			},
+		},
+		{
+			scenario: "when email is masked",
+			rule:     "mask:first=1,until=@",
+			value:    "john@example.com",
+			assert: func(t *testing.T, value interface{}) {
+				assert.Equal(t, "j***@example.com", value)
+			},
+		},
+		{
+			scenario: "when mask char is set",
+			rule:     "mask:first=2,last=2,char=#",
+			value:    "secret",
+			assert: func(t *testing.T, value interface{}) {
+				assert.Equal(t, "se##et", value)
+			},
+		},
+		{
+			scenario: "when matches are replaced with a literal",
+			rule:     `regex:\+?\d[\d -]{7,}\d=>literal:[phone]`,
+			value:    []byte("call me at +49 171 1234567 after 6"),
+			assert: func(t *testing.T, value interface{}) {
+				assert.Equal(t, "call me at [phone] after 6", value)
+			},
+		},
+		{
+			scenario: "when matches are replaced with a faker",
+			rule:     `regex:[\w.]+@[\w.]+=>EmailAddress`,
+			value:    "contact john@example.com",
+			assert: func(t *testing.T, value interface{}) {
+				assert.NotContains(t, value, "john@example.com")
+				assert.Contains(t, value, "contact ")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			transform, ok, err := parseTransformer(test.rule)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			value, err := transform(database.Row{}, test.value)
+			require.NoError(t, err)
+			test.assert(t, value)
+		})
+	}
+}
+
+func TestMaskAndRegexTransformerErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, rule := range []string{"mask:first", "mask:char=ab", "mask:other=1", "regex:[a-z", "regex:abc", "regex:abc=>EmailAdress"} {
+		_, ok, err := parseTransformer(rule)
+		assert.True(t, ok, rule)
+		assert.Error(t, err, rule)
+	}
+}
@@ -0,0 +1,47 @@
+package anonymiser
+
+import (
+	"sort"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/pkg/errors"
+)
+
+// Unclassified returns, by table, the columns of the copied tables which have no anonymisation rule.
+// Columns are classified by either anonymising them or keeping them as-is with the keep rule,
+// tables which data is ignored are not copied and need no classification.
+func Unclassified(rdr reader.Reader, tables config.Tables, globalRules config.Rules) (map[string][]string, error) {
+	tableNames, err := rdr.GetTables()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tables")
+	}
+
+	unclassified := make(map[string][]string)
+	for _, tableName := range tableNames {
+		table, err := tables.FindByName(tableName)
+		if err != nil {
+			table = &config.Table{Name: tableName}
+		}
+
+		if table.IgnoreData {
+			continue
+		}
+
+		columns, err := rdr.GetColumns(tableName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get columns of %s", tableName)
+		}
+
+		rules := globalRules.Merge(table, columns)
+		for _, column := range columns {
+			if _, ok := rules[column]; !ok {
+				unclassified[tableName] = append(unclassified[tableName], column)
+			}
+		}
+
+		sort.Strings(unclassified[tableName])
+	}
+
+	return unclassified, nil
+}
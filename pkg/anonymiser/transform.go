@@ -0,0 +1,239 @@
+package anonymiser
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+)
+
+const (
+	// shiftPrefix shifts dates by a per-entity random offset e.g. shift:30 or shift:30:user_id
+	shiftPrefix = "shift:"
+	// noisePrefix adds bounded noise to numbers e.g. noise:10 or noise:5%
+	noisePrefix = "noise:"
+	// bucketPrefix rounds numbers down to buckets e.g. bucket:10
+	bucketPrefix = "bucket:"
+	// truncatePrefix keeps the first characters of a value e.g. truncate:3
+	truncatePrefix = "truncate:"
+
+	// defaultShiftKey is the column used to identify the entity of a row when shifting dates.
+	defaultShiftKey = "id"
+)
+
+type (
+	// transformer derives an anonymised value from the original value of the column,
+	// row contains the original values of all columns.
+	transformer func(row database.Row, value interface{}) (interface{}, error)
+)
+
+var (
+	// shiftSalt makes the date shifting offsets unpredictable between runs
+	// while keeping them consistent for the same entity during a run.
+	shiftSalt = newSalt()
+
+	dateLayouts = []string{
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04:05.999999999Z07:00",
+		"2006-01-02",
+	}
+)
+
+// parseTransformer returns the transformer for the given rule,
+// ok is false when the rule is not a transformation rule.
+func parseTransformer(rule string) (t transformer, ok bool, err error) {
+	switch {
+	case strings.HasPrefix(rule, shiftPrefix):
+		t, err = newShiftTransformer(strings.TrimPrefix(rule, shiftPrefix))
+	case strings.HasPrefix(rule, noisePrefix):
+		t, err = newNoiseTransformer(strings.TrimPrefix(rule, noisePrefix))
+	case strings.HasPrefix(rule, bucketPrefix):
+		t, err = newBucketTransformer(strings.TrimPrefix(rule, bucketPrefix))
+	case strings.HasPrefix(rule, truncatePrefix):
+		t, err = newTruncateTransformer(strings.TrimPrefix(rule, truncatePrefix))
+	case strings.HasPrefix(rule, maskPrefix):
+		t, err = newMaskTransformer(strings.TrimPrefix(rule, maskPrefix))
+	case strings.HasPrefix(rule, regexPrefix):
+		t, err = newRegexTransformer(strings.TrimPrefix(rule, regexPrefix))
+	default:
+		return nil, false, nil
+	}
+
+	return t, true, err
+}
+
+// shiftKey returns the column identifying the entity of the rows of a shift rule,
+// ok is false when the rule is not a shift rule.
+func shiftKey(rule string) (key string, ok bool) {
+	if !strings.HasPrefix(rule, shiftPrefix) {
+		return "", false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(rule, shiftPrefix), argsSeparator, 2)
+	if len(parts) == 2 && parts[1] != "" {
+		return parts[1], true
+	}
+
+	return defaultShiftKey, true
+}
+
+func newShiftTransformer(args string) (transformer, error) {
+	parts := strings.SplitN(args, argsSeparator, 2)
+
+	days, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || days <= 0 {
+		return nil, errors.Errorf("invalid shift %q, expected a positive number of days", parts[0])
+	}
+
+	key, _ := shiftKey(shiftPrefix + args)
+
+	return func(row database.Row, value interface{}) (interface{}, error) {
+		if value == nil {
+			return nil, nil
+		}
+
+		// A random offset per row would not keep the intervals between the dates of an entity
+		entity, ok := row[key]
+		if !ok {
+			return nil, errors.Errorf("shift key column %s is not read", key)
+		}
+
+		offset := time.Duration(entityOffset(entity, days)) * 24 * time.Hour
+
+		switch v := value.(type) {
+		case time.Time:
+			return v.Add(offset), nil
+		case []byte:
+			return shiftDateString(string(v), offset)
+		case string:
+			return shiftDateString(v, offset)
+		}
+
+		return nil, errors.Errorf("cannot shift value of type %T", value)
+	}, nil
+}
+
+func newNoiseTransformer(args string) (transformer, error) {
+	percentage := strings.HasSuffix(args, "%")
+
+	amount, err := strconv.ParseFloat(strings.TrimSuffix(args, "%"), 64)
+	if err != nil || amount <= 0 {
+		return nil, errors.Errorf("invalid noise %q, expected a positive amount or percentage", args)
+	}
+
+	return numericTransformer(func(n float64) float64 {
+		jitter := amount * (2*mrand.Float64() - 1)
+		if percentage {
+			return n + n*jitter/100
+		}
+
+		return n + jitter
+	}), nil
+}
+
+func newBucketTransformer(args string) (transformer, error) {
+	size, err := strconv.ParseFloat(args, 64)
+	if err != nil || size <= 0 {
+		return nil, errors.Errorf("invalid bucket %q, expected a positive size", args)
+	}
+
+	return numericTransformer(func(n float64) float64 {
+		return math.Floor(n/size) * size
+	}), nil
+}
+
+func newTruncateTransformer(args string) (transformer, error) {
+	length, err := strconv.ParseInt(args, 10, 64)
+	if err != nil || length <= 0 {
+		return nil, errors.Errorf("invalid truncate %q, expected a positive length", args)
+	}
+
+	return func(row database.Row, value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case nil:
+			return nil, nil
+		case []byte:
+			return truncate(string(v), length), nil
+		default:
+			return truncate(toString(v), length), nil
+		}
+	}, nil
+}
+
+// numericTransformer applies fn to numeric values, keeping integers as integers
+// and decimal strings with their number of decimals.
+func numericTransformer(fn func(float64) float64) transformer {
+	return func(row database.Row, value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case nil:
+			return nil, nil
+		case int64:
+			return int64(math.Round(fn(float64(v)))), nil
+		case float64:
+			return fn(v), nil
+		case []byte:
+			return transformNumericString(string(v), fn)
+		case string:
+			return transformNumericString(v, fn)
+		}
+
+		return nil, errors.Errorf("cannot transform value of type %T", value)
+	}
+}
+
+func transformNumericString(str string, fn func(float64) float64) (string, error) {
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return "", errors.Wrapf(err, "%q is not a number", str)
+	}
+
+	decimals := 0
+	if i := strings.Index(str, "."); i >= 0 {
+		decimals = len(str) - i - 1
+	}
+
+	return strconv.FormatFloat(fn(n), 'f', decimals, 64), nil
+}
+
+func shiftDateString(str string, offset time.Duration) (string, error) {
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, str)
+		if err != nil {
+			continue
+		}
+
+		return t.Add(offset).Format(layout), nil
+	}
+
+	return "", errors.Errorf("%q is not a date", str)
+}
+
+// entityOffset returns an offset within ±days which is always the same for the same entity key.
+func entityOffset(key interface{}, days int64) int64 {
+	if b, ok := key.([]byte); ok {
+		key = string(b)
+	}
+
+	h := sha256.New()
+	h.Write(shiftSalt)
+	fmt.Fprintf(h, "%v", key)
+
+	sum := binary.BigEndian.Uint64(h.Sum(nil))
+
+	return int64(sum%uint64(2*days+1)) - days
+}
+
+func newSalt() []byte {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+
+	return salt
+}
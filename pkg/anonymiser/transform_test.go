@@ -0,0 +1,127 @@
+package anonymiser
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftTransformer(t *testing.T) {
+	t.Parallel()
+
+	transform, ok, err := parseTransformer("shift:30:user_id")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	birthDate := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	row := database.Row{"user_id": []byte("42")}
+
+	shifted, err := transform(row, birthDate)
+	require.NoError(t, err)
+	assert.InDelta(t, 0, shifted.(time.Time).Sub(birthDate).Hours(), 30*24)
+
+	createdAt, err := transform(row, []byte("2018-01-01 10:00:00"))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		shifted.(time.Time).Sub(birthDate),
+		mustParse(t, createdAt.(string)).Sub(mustParse(t, "2018-01-01 10:00:00")),
+		"the same entity must be shifted by the same offset",
+	)
+
+	_, err = transform(database.Row{"id": int64(1)}, birthDate)
+	assert.Error(t, err, "a row without the key column must not get a random offset")
+}
+
+func TestNumericTransformers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		rule     string
+		value    interface{}
+		assert   func(*testing.T, interface{})
+	}{
+		{
+			scenario: "when absolute noise is added",
+			rule:     "noise:10",
+			value:    int64(100),
+			assert: func(t *testing.T, value interface{}) {
+				assert.InDelta(t, 100, value.(int64), 10)
+			},
+		},
+		{
+			scenario: "when percentage noise is added to a decimal string",
+			rule:     "noise:5%",
+			value:    []byte("200.00"),
+			assert: func(t *testing.T, value interface{}) {
+				assert.Regexp(t, `^\d+\.\d{2}$`, value)
+				assert.InDelta(t, 200, mustParseFloat(t, value.(string)), 10)
+			},
+		},
+		{
+			scenario: "when age is rounded to a decade",
+			rule:     "bucket:10",
+			value:    int64(37),
+			assert: func(t *testing.T, value interface{}) {
+				assert.Equal(t, int64(30), value)
+			},
+		},
+		{
+			scenario: "when zip code is generalised",
+			rule:     "truncate:3",
+			value:    []byte("10115"),
+			assert: func(t *testing.T, value interface{}) {
+				assert.Equal(t, "101", value)
+			},
+		},
+		{
+			scenario: "when value is nil",
+			rule:     "noise:10",
+			value:    nil,
+			assert: func(t *testing.T, value interface{}) {
+				assert.Nil(t, value)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			transform, ok, err := parseTransformer(test.rule)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			value, err := transform(database.Row{}, test.value)
+			require.NoError(t, err)
+			test.assert(t, value)
+		})
+	}
+}
+
+func TestParseTransformerErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, rule := range []string{"shift:abc", "noise:-1", "bucket:0", "truncate:x"} {
+		_, ok, err := parseTransformer(rule)
+		assert.True(t, ok, rule)
+		assert.Error(t, err, rule)
+	}
+}
+
+func mustParse(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse("2006-01-02 15:04:05", value)
+	require.NoError(t, err)
+
+	return parsed
+}
+
+func mustParseFloat(t *testing.T, value string) float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	require.NoError(t, err)
+
+	return f
+}
@@ -0,0 +1,317 @@
+package anonymiser
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+)
+
+const (
+	// uuidFaker generates a random version 4 UUID
+	uuidFaker = "UUID"
+	// integerFaker generates a random integer, optionally within a range e.g. Integer:1:100
+	integerFaker = "Integer"
+	// dateFaker generates a random date, optionally within a window e.g. Date:1950-01-01:2000-12-31
+	dateFaker = "Date"
+
+	argsSeparator = ":"
+	dateLayout    = "2006-01-02"
+)
+
+type columnKind int
+
+const (
+	kindText columnKind = iota
+	kindInteger
+	kindDecimal
+	kindBoolean
+	kindTime
+	kindUUID
+)
+
+var (
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// integerBounds are the maximum values of the signed integer database types.
+	integerBounds = map[string]int64{
+		"TINYINT":     math.MaxInt8,
+		"SMALLINT":    math.MaxInt16,
+		"INT2":        math.MaxInt16,
+		"SMALLSERIAL": math.MaxInt16,
+		"MEDIUMINT":   1<<23 - 1,
+		"INT":         math.MaxInt32,
+		"INTEGER":     math.MaxInt32,
+		"INT4":        math.MaxInt32,
+		"SERIAL":      math.MaxInt32,
+		"BIGINT":      math.MaxInt64,
+		"INT8":        math.MaxInt64,
+		"BIGSERIAL":   math.MaxInt64,
+		"YEAR":        9999,
+	}
+
+	// dateWindow is the default window used by the Date faker.
+	dateWindow = 10 * 365 * 24 * time.Hour
+)
+
+// AnonymiseColumn generates a fake value that fits the given column type.
+// An error is returned when the faker is unknown or cannot produce a value of the column type.
+func AnonymiseColumn(fakerType string, column *database.ColumnType) (interface{}, error) {
+	value, err := generate(fakerType, column)
+	if err != nil {
+		return nil, err
+	}
+
+	return conform(fakerType, value, column)
+}
+
+func generate(fakerType string, column *database.ColumnType) (interface{}, error) {
+	args := strings.Split(fakerType, argsSeparator)
+	name, args := args[0], args[1:]
+
+	switch name {
+	case uuidFaker:
+		return newUUID()
+	case integerFaker:
+		return randomInteger(args, column)
+	case dateFaker:
+		return randomDate(args)
+	}
+
+	faker, ok := Functions[fakerType]
+	if !ok {
+		return nil, errors.Errorf("unknown faker %q", fakerType)
+	}
+
+	if faker.Type().NumIn() > 0 {
+		return nil, errors.Errorf("faker %q requires arguments and cannot be used", fakerType)
+	}
+
+	return callFaker(fakerType, faker), nil
+}
+
+// conform converts the generated value into the column type, failing if it cannot be represented.
+func conform(fakerType string, value interface{}, column *database.ColumnType) (interface{}, error) {
+	if column == nil {
+		return value, nil
+	}
+
+	fail := errors.Errorf(
+		"faker %q cannot produce a value for column %q of type %s",
+		fakerType,
+		column.Name,
+		column.DatabaseType,
+	)
+
+	switch kindOf(column) {
+	case kindInteger:
+		var n int64
+		switch v := value.(type) {
+		case int64:
+			n = v
+		case string:
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fail
+			}
+			n = parsed
+		default:
+			return nil, fail
+		}
+
+		min, max := integerRange(column)
+		if n > max || n < min {
+			return nil, errors.Wrapf(fail, "%d is out of range", n)
+		}
+
+		return n, nil
+	case kindDecimal:
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case string:
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fail
+			}
+			return parsed, nil
+		}
+	case kindBoolean:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case kindTime:
+		if v, ok := value.(time.Time); ok {
+			return v, nil
+		}
+	case kindUUID:
+		if v, ok := value.(string); ok && uuidRegex.MatchString(v) {
+			return v, nil
+		}
+	default:
+		return truncate(toString(value), column.Length), nil
+	}
+
+	return nil, fail
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(dateLayout)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// typeName returns the normalised database type name of the column, without its signedness.
+func typeName(column *database.ColumnType) string {
+	name := strings.ToUpper(column.DatabaseType)
+	name = strings.TrimPrefix(name, "UNSIGNED ")
+
+	return strings.TrimSuffix(name, " UNSIGNED")
+}
+
+// isUnsigned checks if the column holds unsigned integers.
+func isUnsigned(column *database.ColumnType) bool {
+	name := strings.ToUpper(column.DatabaseType)
+
+	return strings.HasPrefix(name, "UNSIGNED ") || strings.HasSuffix(name, " UNSIGNED")
+}
+
+func kindOf(column *database.ColumnType) columnKind {
+	name := typeName(column)
+
+	if _, ok := integerBounds[name]; ok {
+		return kindInteger
+	}
+
+	switch name {
+	case "DECIMAL", "NUMERIC", "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "REAL":
+		return kindDecimal
+	case "BOOL", "BOOLEAN":
+		return kindBoolean
+	case "DATE", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return kindTime
+	case "UUID":
+		return kindUUID
+	}
+
+	return kindText
+}
+
+// IsText checks if the column holds text values.
+func IsText(column *database.ColumnType) bool {
+	return kindOf(column) == kindText
+}
+
+// integerRange returns the minimum and maximum values of the integer column.
+func integerRange(column *database.ColumnType) (int64, int64) {
+	if column == nil || kindOf(column) != kindInteger {
+		return -math.MaxInt32 - 1, math.MaxInt32
+	}
+
+	max := integerBounds[typeName(column)]
+	if !isUnsigned(column) {
+		return -max - 1, max
+	}
+
+	// Unsigned BIGINT values above the int64 range cannot be generated
+	if max == math.MaxInt64 {
+		return 0, max
+	}
+
+	return 0, 2*max + 1
+}
+
+func randomInteger(args []string, column *database.ColumnType) (int64, error) {
+	_, max := integerRange(column)
+	min := int64(0)
+
+	if len(args) > 0 {
+		if len(args) != 2 {
+			return 0, errors.Errorf("%s expects a range in the format %s:min:max", integerFaker, integerFaker)
+		}
+
+		var err error
+		if min, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+			return 0, errors.Wrapf(err, "invalid %s minimum", integerFaker)
+		}
+		if max, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return 0, errors.Wrapf(err, "invalid %s maximum", integerFaker)
+		}
+	}
+
+	if min > max {
+		return 0, errors.Errorf("%s minimum %d is greater than maximum %d", integerFaker, min, max)
+	}
+
+	if max-min < 0 || max-min == math.MaxInt64 {
+		return min + mrand.Int63(), nil
+	}
+
+	return min + mrand.Int63n(max-min+1), nil
+}
+
+func randomDate(args []string) (time.Time, error) {
+	to := time.Now().UTC()
+	from := to.Add(-dateWindow)
+
+	if len(args) > 0 {
+		if len(args) != 2 {
+			return time.Time{}, errors.Errorf("%s expects a window in the format %s:%s:%s", dateFaker, dateFaker, dateLayout, dateLayout)
+		}
+
+		var err error
+		if from, err = time.Parse(dateLayout, args[0]); err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid %s start", dateFaker)
+		}
+		if to, err = time.Parse(dateLayout, args[1]); err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid %s end", dateFaker)
+		}
+	}
+
+	if from.After(to) {
+		return time.Time{}, errors.Errorf("%s start is after its end", dateFaker)
+	}
+
+	// Durations overflow for windows of more than 292 years, days don't
+	days := (to.Unix() - from.Unix()) / int64(24*time.Hour/time.Second)
+	offset := mrand.Int63n(days + 1)
+
+	return from.AddDate(0, 0, int(offset)).Truncate(24 * time.Hour), nil
+}
+
+// newUUID generates a random (version 4) UUID.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate uuid")
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// truncate cuts the string to the maximum number of characters, a length of 0 means unbounded.
+func truncate(str string, length int64) string {
+	if length <= 0 || int64(utf8.RuneCountInString(str)) <= length {
+		return str
+	}
+
+	return string([]rune(str)[:length])
+}
@@ -0,0 +1,134 @@
+package anonymiser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymiseColumn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario  string
+		fakerType string
+		column    *database.ColumnType
+		assert    func(*testing.T, interface{})
+	}{
+		{
+			scenario:  "when string is cut to the column length",
+			fakerType: "Paragraph",
+			column:    &database.ColumnType{Name: "bio", DatabaseType: "VARCHAR", Length: 10},
+			assert: func(t *testing.T, value interface{}) {
+				assert.Len(t, []rune(value.(string)), 10)
+			},
+		},
+		{
+			scenario:  "when integer is within range",
+			fakerType: "Integer:18:99",
+			column:    &database.ColumnType{Name: "age", DatabaseType: "INT"},
+			assert: func(t *testing.T, value interface{}) {
+				assert.True(t, value.(int64) >= 18 && value.(int64) <= 99)
+			},
+		},
+		{
+			scenario:  "when numeric faker is used for an integer column",
+			fakerType: "MonthNum",
+			column:    &database.ColumnType{Name: "month", DatabaseType: "SMALLINT"},
+			assert: func(t *testing.T, value interface{}) {
+				assert.IsType(t, int64(0), value)
+			},
+		},
+		{
+			scenario:  "when date is within the window",
+			fakerType: "Date:2000-01-01:2000-12-31",
+			column:    &database.ColumnType{Name: "birth_date", DatabaseType: "DATE"},
+			assert: func(t *testing.T, value interface{}) {
+				assert.Equal(t, 2000, value.(time.Time).Year())
+			},
+		},
+		{
+			scenario:  "when date window is longer than a duration",
+			fakerType: "Date:1700-01-01:2020-01-01",
+			column:    &database.ColumnType{Name: "birth_date", DatabaseType: "DATE"},
+			assert: func(t *testing.T, value interface{}) {
+				assert.True(t, value.(time.Time).Year() >= 1700 && value.(time.Time).Year() <= 2020)
+			},
+		},
+		{
+			scenario:  "when integer is within the unsigned range",
+			fakerType: "Integer:200:255",
+			column:    &database.ColumnType{Name: "flag", DatabaseType: "UNSIGNED TINYINT"},
+			assert: func(t *testing.T, value interface{}) {
+				assert.True(t, value.(int64) >= 200 && value.(int64) <= 255)
+			},
+		},
+		{
+			scenario:  "when uuid is generated",
+			fakerType: "UUID",
+			column:    &database.ColumnType{Name: "id", DatabaseType: "UUID"},
+			assert: func(t *testing.T, value interface{}) {
+				assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, value)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			value, err := AnonymiseColumn(test.fakerType, test.column)
+			require.NoError(t, err)
+			test.assert(t, value)
+		})
+	}
+}
+
+func TestAnonymiseColumnErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario  string
+		fakerType string
+		column    *database.ColumnType
+	}{
+		{
+			scenario:  "when faker is unknown",
+			fakerType: "EmailAdress",
+			column:    &database.ColumnType{Name: "email", DatabaseType: "VARCHAR"},
+		},
+		{
+			scenario:  "when faker cannot produce an integer",
+			fakerType: "FirstName",
+			column:    &database.ColumnType{Name: "age", DatabaseType: "INT"},
+		},
+		{
+			scenario:  "when integer is out of the column range",
+			fakerType: "Integer:200:1000",
+			column:    &database.ColumnType{Name: "flag", DatabaseType: "TINYINT"},
+		},
+		{
+			scenario:  "when integer is negative for an unsigned column",
+			fakerType: "Integer:-10:-1",
+			column:    &database.ColumnType{Name: "flag", DatabaseType: "TINYINT UNSIGNED"},
+		},
+		{
+			scenario:  "when faker cannot produce a uuid",
+			fakerType: "Word",
+			column:    &database.ColumnType{Name: "id", DatabaseType: "UUID"},
+		},
+		{
+			scenario:  "when faker cannot produce a date",
+			fakerType: "Month",
+			column:    &database.ColumnType{Name: "created_at", DatabaseType: "TIMESTAMP"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			_, err := AnonymiseColumn(test.fakerType, test.column)
+			assert.Error(t, err)
+		})
+	}
+}
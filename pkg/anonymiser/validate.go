@@ -0,0 +1,181 @@
+package anonymiser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/pkg/errors"
+)
+
+// cardinalitySamples is the number of values generated to find out if a faker produces few values.
+const cardinalitySamples = 1000
+
+// Validate checks every anonymisation rule of the configuration before any data is read,
+// returning all the problems found.
+func Validate(rdr reader.Reader, tables config.Tables, globalRules config.Rules) ([]error, error) {
+	var problems []error
+
+	for _, pattern := range sortedKeys(globalRules) {
+		if err := config.ValidatePattern(pattern); err != nil {
+			problems = append(problems, errors.Wrapf(err, "invalid global rule pattern %q", pattern))
+			continue
+		}
+
+		if err := ValidateRule(globalRules[pattern]); err != nil {
+			problems = append(problems, errors.Wrapf(err, "invalid global rule for %q", pattern))
+		}
+	}
+
+	tableNames, err := rdr.GetTables()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tables")
+	}
+
+	existing := make(map[string]bool, len(tableNames))
+	for _, tableName := range tableNames {
+		existing[tableName] = true
+	}
+
+	for _, table := range tables {
+		if len(table.Anonymise) == 0 {
+			continue
+		}
+
+		if !existing[table.Name] {
+			problems = append(problems, errors.Errorf("table %s does not exist", table.Name))
+			continue
+		}
+
+		columns, err := rdr.GetColumns(table.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get columns of %s", table.Name)
+		}
+
+		columnExists := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			columnExists[column] = true
+		}
+
+		for _, column := range sortedKeys(table.Anonymise) {
+			if !columnExists[column] {
+				problems = append(problems, errors.Errorf("column %s does not exist", RuleKey(table.Name, column)))
+				continue
+			}
+
+			if err := ValidateRule(table.Anonymise[column]); err != nil {
+				problems = append(problems, errors.Wrapf(err, "invalid rule for %s", RuleKey(table.Name, column)))
+				continue
+			}
+
+			if key, ok := shiftKey(table.Anonymise[column]); ok && !columnExists[key] {
+				problems = append(problems, errors.Errorf("shift key column %s of %s does not exist", key, RuleKey(table.Name, column)))
+			}
+		}
+	}
+
+	// The global shift rules need the key column in every table they apply to
+	if !hasShiftRule(globalRules) {
+		return problems, nil
+	}
+
+	for _, tableName := range tableNames {
+		columns, err := rdr.GetColumns(tableName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get columns of %s", tableName)
+		}
+
+		table, err := tables.FindByName(tableName)
+		if err != nil {
+			table = &config.Table{Name: tableName}
+		}
+
+		columnExists := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			columnExists[column] = true
+		}
+
+		for _, column := range columns {
+			rule, ok := globalRules.Find(tableName, column)
+			if _, overridden := table.Anonymise[column]; !ok || overridden {
+				continue
+			}
+
+			if key, ok := shiftKey(rule); ok && !columnExists[key] {
+				problems = append(problems, errors.Errorf("shift key column %s of %s does not exist", key, RuleKey(tableName, column)))
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+func hasShiftRule(rules config.Rules) bool {
+	for _, rule := range rules {
+		if _, ok := shiftKey(rule); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateRule checks that an anonymisation rule can be applied.
+func ValidateRule(rule string) error {
+	if rule == KeepRule || strings.HasPrefix(rule, literalPrefix) {
+		return nil
+	}
+
+	if strings.HasPrefix(rule, conditionalPrefix) {
+		_, err := compileCondition(rule)
+		return err
+	}
+
+	if _, ok, err := parseTransformer(rule); ok {
+		return err
+	}
+
+	_, err := generate(rule, nil)
+	return err
+}
+
+// IsFaker checks if the rule replaces the values with random ones, other rules derive them from the source values.
+func IsFaker(rule string) bool {
+	if rule == KeepRule || strings.HasPrefix(rule, literalPrefix) || strings.HasPrefix(rule, conditionalPrefix) {
+		return false
+	}
+
+	if _, ok, _ := parseTransformer(rule); ok {
+		return false
+	}
+
+	_, err := generate(rule, nil)
+	return err == nil
+}
+
+// HasFewValues checks if the faker only produces a small set of values for the column, like booleans or a short
+// integer range, so that its values often equal the source values by chance. The values are sampled to find out.
+func HasFewValues(rule string, column *database.ColumnType) bool {
+	distinct := make(map[string]bool, cardinalitySamples)
+	for i := 0; i < cardinalitySamples; i++ {
+		value, err := AnonymiseColumn(rule, column)
+		if err != nil {
+			return false
+		}
+		distinct[toString(value)] = true
+	}
+
+	return len(distinct) < cardinalitySamples/2
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
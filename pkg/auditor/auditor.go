@@ -0,0 +1,454 @@
+package auditor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/anonymiser"
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/hellofresh/klepto/pkg/scanner"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CheckValues verifies that the values of an anonymised column are not found in the source column.
+	CheckValues = "anonymised values"
+	// CheckFreeText verifies that source emails and phone numbers are not found in free text.
+	CheckFreeText = "free text"
+	// CheckNotRandomised reports the columns whose rule derives the values from the source values, they are not compared.
+	CheckNotRandomised = "not randomised"
+	// CheckNoPrimaryKey reports the anonymised columns of tables without a primary key, their rows cannot be matched.
+	CheckNoPrimaryKey = "no primary key"
+	// CheckNotComparable reports the columns whose faker produces few values, which often equal the source values by chance.
+	CheckNotComparable = "not comparable"
+)
+
+type (
+	// Auditor checks a stolen database for personal data leaked from its source.
+	Auditor struct {
+		source reader.Reader
+		target reader.Reader
+		spec   *config.Spec
+		// sampleSize is the number of rows read from each table, 0 reads all rows.
+		sampleSize uint64
+	}
+
+	// Result is the outcome of a check on a target column.
+	Result struct {
+		// Check is the kind of check performed.
+		Check string
+		// Table is the table name.
+		Table string
+		// Column is the column name.
+		Column string
+		// Rule is the anonymisation rule of the column.
+		Rule string
+		// Checked is the number of target values checked.
+		Checked int
+		// Leaks is the number of target values containing source data.
+		Leaks int
+	}
+
+	// auditedRow are the anonymised values of a target row and the values of the same source row.
+	auditedRow struct {
+		target database.Row
+		source database.Row
+	}
+
+	// personalData are the emails and phone numbers found in the source text columns.
+	personalData struct {
+		emails map[string]bool
+		phones map[string]bool
+	}
+)
+
+var (
+	emailInTextRegex = regexp.MustCompile(`[^@\s"'<>(),;:]+@[^@\s"'<>(),;:]+\.[a-zA-Z]{2,}`)
+	phoneInTextRegex = regexp.MustCompile(`\+?\d[\d\s().-]{5,18}\d`)
+)
+
+// New creates a new auditor.
+func New(source reader.Reader, target reader.Reader, spec *config.Spec, sampleSize uint64) *Auditor {
+	return &Auditor{source: source, target: target, spec: spec, sampleSize: sampleSize}
+}
+
+// Audit checks that no anonymised column of the target holds a source value
+// and that no source email or phone number appears in the target free text columns.
+func (a *Auditor) Audit() ([]*Result, error) {
+	tables, err := a.source.GetTables()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tables")
+	}
+
+	schema, err := a.source.GetSchema()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the primary keys")
+	}
+
+	var results []*Result
+	for _, tableName := range tables {
+		var primaryKey []string
+		if tableSchema := schema.Table(tableName); tableSchema != nil {
+			primaryKey = tableSchema.PrimaryKey
+		}
+
+		tableResults, err := a.auditTable(tableName, primaryKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to audit table %s", tableName)
+		}
+
+		results = append(results, tableResults...)
+	}
+
+	found, err := a.collectPersonalData(tables)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found.emails) == 0 && len(found.phones) == 0 {
+		return results, nil
+	}
+
+	for _, tableName := range tables {
+		tableResults, err := a.auditFreeText(tableName, found)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to audit free text of table %s", tableName)
+		}
+
+		results = append(results, tableResults...)
+	}
+
+	return results, nil
+}
+
+// auditTable compares the anonymised columns of the target rows with the same source rows.
+func (a *Auditor) auditTable(tableName string, primaryKey []string) ([]*Result, error) {
+	table := a.tableConfig(tableName)
+	if table.IgnoreData {
+		return nil, nil
+	}
+
+	rules, err := anonymiser.TableRules(a.source, table, a.spec.Anonymise)
+	if err != nil {
+		return nil, err
+	}
+
+	columnTypes, err := a.columnTypes(a.source, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		columns []string
+		results []*Result
+	)
+	for _, column := range sortedKeys(rules) {
+		rule := rules[column]
+		switch {
+		case rule == anonymiser.KeepRule:
+		case !anonymiser.IsFaker(rule):
+			// Derived values are expected to resemble the source values
+			results = append(results, &Result{Check: CheckNotRandomised, Table: tableName, Column: column, Rule: rule})
+		case anonymiser.HasFewValues(rule, columnTypes[column]):
+			results = append(results, &Result{Check: CheckNotComparable, Table: tableName, Column: column, Rule: rule})
+		case len(primaryKey) == 0:
+			results = append(results, &Result{Check: CheckNoPrimaryKey, Table: tableName, Column: column, Rule: rule})
+		default:
+			columns = append(columns, column)
+		}
+	}
+
+	if len(columns) == 0 {
+		return results, nil
+	}
+
+	log.WithField("table", tableName).Debug("auditing anonymised columns")
+
+	rows, err := a.readRows(table, primaryKey, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, column := range columns {
+		targetColumn := table.Transform.ColumnName(column)
+		result := &Result{Check: CheckValues, Table: tableName, Column: column, Rule: rules[column]}
+		for _, row := range rows {
+			sourceValue := toString(row.source[column])
+			targetValue := toString(row.target[targetColumn])
+			if targetValue == "" {
+				continue
+			}
+
+			result.Checked++
+			if targetValue == sourceValue {
+				result.Leaks++
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// readRows reads the target rows, limited to the sample size, and the source rows with the same primary key.
+func (a *Auditor) readRows(table *config.Table, primaryKey []string, columns []string) ([]*auditedRow, error) {
+	targetName := table.Transform.TableName(table.Name)
+	targetKey := make([]string, len(primaryKey))
+	for i, column := range primaryKey {
+		targetKey[i] = table.Transform.ColumnName(column)
+	}
+
+	targetColumns := make([]string, len(columns))
+	for i, column := range columns {
+		targetColumns[i] = table.Transform.ColumnName(column)
+	}
+
+	rows := make(map[string]*auditedRow)
+	err := a.read(a.target, targetName, concat(targetKey, targetColumns), a.sampleSize, func(row database.Row) {
+		rows[rowKey(row, targetKey)] = &auditedRow{target: row}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read target")
+	}
+
+	// The whole source is read, as the sampled target rows can be anywhere in it
+	err = a.read(a.source, table.Name, concat(primaryKey, columns), 0, func(row database.Row) {
+		if audited, ok := rows[rowKey(row, primaryKey)]; ok {
+			audited.source = row
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read source")
+	}
+
+	matched := make([]*auditedRow, 0, len(rows))
+	for _, key := range sortedRowKeys(rows) {
+		if rows[key].source != nil {
+			matched = append(matched, rows[key])
+		}
+	}
+
+	return matched, nil
+}
+
+// collectPersonalData reads the text columns of all source rows and returns the emails they contain,
+// and the phone numbers of the columns that are anonymised or named like phone numbers.
+// The columns kept on purpose with the keep rule are ignored.
+func (a *Auditor) collectPersonalData(tables []string) (personalData, error) {
+	found := personalData{emails: make(map[string]bool), phones: make(map[string]bool)}
+	for _, tableName := range tables {
+		rules, err := anonymiser.TableRules(a.source, a.tableConfig(tableName), a.spec.Anonymise)
+		if err != nil {
+			return found, err
+		}
+
+		columnTypes, err := a.source.GetColumnTypes(tableName)
+		if err != nil {
+			return found, errors.Wrapf(err, "failed to get column types of %s", tableName)
+		}
+
+		var columns []string
+		phoneColumns := make(map[string]bool)
+		for _, columnType := range columnTypes {
+			rule, anonymised := rules[columnType.Name]
+			if !anonymiser.IsText(columnType) || rule == anonymiser.KeepRule {
+				continue
+			}
+
+			columns = append(columns, columnType.Name)
+			phoneColumns[columnType.Name] = anonymised || scanner.IsPhoneColumn(columnType.Name)
+		}
+
+		if len(columns) == 0 {
+			continue
+		}
+
+		log.WithField("table", tableName).Debug("collecting personal data")
+
+		err = a.read(a.source, tableName, columns, 0, func(row database.Row) {
+			for _, column := range columns {
+				value := toString(row[column])
+				for _, email := range emailInTextRegex.FindAllString(value, -1) {
+					found.emails[strings.ToLower(email)] = true
+				}
+
+				if phoneColumns[column] && scanner.IsPhoneNumber(value) {
+					found.phones[scanner.PhoneDigits(value)] = true
+				}
+			}
+		})
+		if err != nil {
+			return found, errors.Wrapf(err, "failed to read source table %s", tableName)
+		}
+	}
+
+	return found, nil
+}
+
+// auditFreeText looks for source emails and phone numbers in the text columns of the target table.
+func (a *Auditor) auditFreeText(tableName string, found personalData) ([]*Result, error) {
+	// Transformed tables are written under their target name
+	targetName := a.tableConfig(tableName).Transform.TableName(tableName)
+	columnTypes, err := a.target.GetColumnTypes(targetName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get column types")
+	}
+
+	var columns []string
+	for _, columnType := range columnTypes {
+		if anonymiser.IsText(columnType) {
+			columns = append(columns, columnType.Name)
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	log.WithField("table", tableName).Debug("auditing free text columns")
+
+	results := make(map[string]*Result, len(columns))
+	for _, column := range columns {
+		results[column] = &Result{Check: CheckFreeText, Table: tableName, Column: column}
+	}
+
+	err = a.read(a.target, targetName, columns, a.sampleSize, func(row database.Row) {
+		for _, column := range columns {
+			value := toString(row[column])
+			if value == "" {
+				continue
+			}
+
+			results[column].Checked++
+			if found.containedIn(value) {
+				results[column].Leaks++
+			}
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read target")
+	}
+
+	ordered := make([]*Result, len(columns))
+	for i, column := range columns {
+		ordered[i] = results[column]
+	}
+
+	return ordered, nil
+}
+
+// columnTypes returns the types of the columns of the table by column name.
+func (a *Auditor) columnTypes(rdr reader.Reader, tableName string) (map[string]*database.ColumnType, error) {
+	columnTypes, err := rdr.GetColumnTypes(tableName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get column types")
+	}
+
+	types := make(map[string]*database.ColumnType, len(columnTypes))
+	for _, columnType := range columnTypes {
+		types[columnType.Name] = columnType
+	}
+
+	return types, nil
+}
+
+func (a *Auditor) tableConfig(tableName string) *config.Table {
+	table, err := a.spec.Tables.FindByName(tableName)
+	if err != nil {
+		return &config.Table{Name: tableName}
+	}
+
+	return table
+}
+
+// read reads the columns of the table, passing every row to the callback. A limit of 0 reads all rows.
+func (a *Auditor) read(rdr reader.Reader, tableName string, columns []string, limit uint64, fn func(database.Row)) error {
+	formatted := make([]string, len(columns))
+	for i, column := range columns {
+		formatted[i] = rdr.FormatColumn(tableName, column)
+	}
+
+	rowChan := make(chan database.Row)
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- rdr.ReadTable(tableName, rowChan, reader.ReadTableOpt{Columns: formatted, Limit: limit}, nil)
+	}()
+
+	for row := range rowChan {
+		fn(row)
+	}
+
+	return <-errChan
+}
+
+// rowKey returns the primary key of the row as a string.
+func rowKey(row database.Row, primaryKey []string) string {
+	values := make([]string, len(primaryKey))
+	for i, column := range primaryKey {
+		values[i] = toString(row[column])
+	}
+
+	return strings.Join(values, "\x00")
+}
+
+// concat returns the columns of both lists in a new list.
+func concat(columns []string, others []string) []string {
+	return append(append(make([]string, 0, len(columns)+len(others)), columns...), others...)
+}
+
+func sortedRowKeys(rows map[string]*auditedRow) []string {
+	keys := make([]string, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// containedIn checks if any of the personal data is found in the text.
+func (p personalData) containedIn(text string) bool {
+	for _, email := range emailInTextRegex.FindAllString(text, -1) {
+		if p.emails[strings.ToLower(email)] {
+			return true
+		}
+	}
+
+	for _, phone := range phoneInTextRegex.FindAllString(text, -1) {
+		if p.phones[scanner.PhoneDigits(phone)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return strings.TrimSpace(string(v))
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
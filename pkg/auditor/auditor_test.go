@@ -0,0 +1,147 @@
+package auditor
+
+import (
+	"testing"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	t.Parallel()
+
+	source := &mockReader{rows: []database.Row{
+		{"id": int64(1), "email": []byte("john@example.com"), "phone": []byte("+49 30 1234567"), "age": int64(34), "notes": []byte("")},
+		{"id": int64(2), "email": []byte("jane@example.com"), "phone": []byte("+49 30 7654321"), "age": int64(27), "notes": []byte("")},
+	}}
+	target := &mockReader{rows: []database.Row{
+		{"id": int64(1), "email": []byte("jane@example.com"), "phone": []byte("+1 555 0000000"), "age": int64(30), "notes": []byte("Call +49 (30) 7654321 first"), "contact": []byte("by mail")},
+		{"id": int64(2), "email": []byte("jane@example.com"), "phone": []byte("+1 555 1111111"), "age": int64(20), "notes": []byte("Wrote to JOHN@example.com"), "contact": []byte("by phone")},
+	}}
+
+	spec := &config.Spec{
+		Tables: config.Tables{{Name: "users", Anonymise: map[string]string{"email": "EmailAddress", "phone": "Phone", "age": "bucket:10"}}},
+	}
+
+	results, err := New(source, target, spec, 0).Audit()
+	require.NoError(t, err)
+
+	leaks := make(map[string]int)
+	for _, result := range results {
+		if result.Check == CheckNotRandomised {
+			assert.Equal(t, "age", result.Column)
+			continue
+		}
+
+		assert.Equal(t, 2, result.Checked)
+		leaks[result.Check+" "+result.Column] = result.Leaks
+	}
+
+	assert.Equal(t, map[string]int{
+		CheckValues + " email":     1,
+		CheckValues + " phone":     0,
+		CheckFreeText + " email":   2,
+		CheckFreeText + " phone":   0,
+		CheckFreeText + " notes":   2,
+		CheckFreeText + " contact": 0,
+	}, leaks)
+}
+
+func TestAuditCollectsPersonalDataFromTheWholeSource(t *testing.T) {
+	t.Parallel()
+
+	source := &mockReader{rows: []database.Row{
+		{"id": int64(1), "email": []byte("john@example.com"), "phone": []byte("+49 30 1234567"), "score": int64(2), "contact": []byte("")},
+		{"id": int64(2), "email": []byte("jane@example.com"), "phone": []byte("+49 30 7654321"), "score": int64(3), "contact": []byte("ask bob@example.com")},
+	}}
+	target := &mockReader{rows: []database.Row{
+		{"id": int64(1), "email": []byte("anne@example.org"), "phone": []byte("+1 555 0000000"), "score": int64(2), "notes": []byte("Call +49 (30) 7654321 or bob@example.com")},
+		{"id": int64(2), "email": []byte("paul@example.org"), "phone": []byte("+1 555 1111111"), "score": int64(3), "notes": []byte("")},
+	}}
+
+	spec := &config.Spec{
+		Tables: config.Tables{{Name: "users", Anonymise: map[string]string{"email": "EmailAddress", "phone": "Phone", "score": "Integer:1:3"}}},
+	}
+
+	results, err := New(source, target, spec, 1).Audit()
+	require.NoError(t, err)
+
+	leaks := make(map[string]int)
+	for _, result := range results {
+		leaks[result.Check+" "+result.Column] = result.Leaks
+	}
+
+	assert.Equal(t, map[string]int{
+		CheckValues + " email":        0,
+		CheckValues + " phone":        0,
+		CheckNotComparable + " score": 0,
+		CheckFreeText + " email":      0,
+		CheckFreeText + " phone":      0,
+		CheckFreeText + " notes":      1,
+		CheckFreeText + " contact":    0,
+	}, leaks)
+}
+
+func TestAuditWithoutPrimaryKey(t *testing.T) {
+	t.Parallel()
+
+	rows := []database.Row{{"email": []byte("john@example.com")}}
+	spec := &config.Spec{
+		Tables: config.Tables{{Name: "users", Anonymise: map[string]string{"email": "EmailAddress"}}},
+	}
+
+	results, err := New(&mockReader{rows: rows, noKey: true}, &mockReader{rows: rows}, spec, 0).Audit()
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, CheckNoPrimaryKey, results[0].Check)
+	assert.Zero(t, results[0].Leaks)
+}
+
+type mockReader struct {
+	reader.Reader
+	rows  []database.Row
+	noKey bool
+}
+
+func (m *mockReader) GetSchema() (*database.Schema, error) {
+	table := &database.TableSchema{Name: "users", PrimaryKey: []string{"id"}}
+	if m.noKey {
+		table.PrimaryKey = nil
+	}
+
+	return &database.Schema{Tables: []*database.TableSchema{table}}, nil
+}
+
+func (m *mockReader) GetTables() ([]string, error) { return []string{"users"}, nil }
+func (m *mockReader) FormatColumn(tbl string, col string) string {
+	return col
+}
+func (m *mockReader) GetColumnTypes(string) ([]*database.ColumnType, error) {
+	return []*database.ColumnType{
+		{Name: "email", DatabaseType: "VARCHAR"},
+		{Name: "phone", DatabaseType: "VARCHAR"},
+		{Name: "notes", DatabaseType: "TEXT"},
+		{Name: "contact", DatabaseType: "TEXT"},
+		{Name: "score", DatabaseType: "INT"},
+	}, nil
+}
+func (m *mockReader) ReadTable(tableName string, rowChan chan<- database.Row, opts reader.ReadTableOpt, matchers config.Matchers) error {
+	defer close(rowChan)
+
+	for i, row := range m.rows {
+		if opts.Limit > 0 && uint64(i) >= opts.Limit {
+			break
+		}
+
+		selected := make(database.Row, len(opts.Columns))
+		for _, column := range opts.Columns {
+			selected[column] = row[column]
+		}
+		rowChan <- selected
+	}
+
+	return nil
+}
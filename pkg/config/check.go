@@ -0,0 +1,276 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type (
+	// Problem is an unknown or mistyped key found in a config file.
+	Problem struct {
+		// File is the config file, empty when the config was not read from a file.
+		File string
+		// Line is the line of the key in the file, 0 when it could not be found.
+		Line int
+		// Path is the path of the key e.g. Tables[1].Filter.Limit
+		Path string
+		// Message describes the problem.
+		Message string
+	}
+
+	// checker walks a decoded config file comparing it with the Spec type.
+	checker struct {
+		positions positions
+		problems  []*Problem
+	}
+)
+
+func (p *Problem) Error() string {
+	location := p.File
+	if p.Line > 0 {
+		location = strings.TrimPrefix(fmt.Sprintf("%s line %d", p.File, p.Line), " ")
+	}
+
+	if location == "" {
+		return fmt.Sprintf("%s: %s", p.Path, p.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", location, p.Path, p.Message)
+}
+
+// CheckFile decodes a TOML, YAML or JSON config file and returns its unknown or mistyped keys.
+func CheckFile(filename string) ([]*Problem, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	return Check(content, strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// Check decodes a config in the given format (toml, yaml or json) and returns its unknown or mistyped keys.
+func Check(content []byte, format string) ([]*Problem, error) {
+	var (
+		decoded interface{}
+		err     error
+	)
+
+	switch strings.ToLower(format) {
+	case "toml":
+		var m map[string]interface{}
+		_, err = toml.Decode(string(content), &m)
+		decoded = m
+	case "yaml", "yml":
+		var m map[interface{}]interface{}
+		err = yaml.Unmarshal(content, &m)
+		decoded = normaliseYAML(m)
+	case "json":
+		var m map[string]interface{}
+		err = json.Unmarshal(content, &m)
+		decoded = m
+	default:
+		// Other formats supported by viper are not checked
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s config", format)
+	}
+
+	c := &checker{positions: locate(content, format)}
+	c.check("", decoded, reflect.TypeOf(Spec{}))
+
+	sort.SliceStable(c.problems, func(i, j int) bool { return c.problems[i].Line < c.problems[j].Line })
+
+	return c.problems, nil
+}
+
+// check compares the value with the type, path is the path of the value in the file.
+func (c *checker) check(path string, value interface{}, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			c.mistyped(path, "a table", value)
+			return
+		}
+
+		for _, name := range sortedKeys(m) {
+			field, ok := fieldByName(t, name)
+			if !ok {
+				c.report(join(path, name), fmt.Sprintf("unknown key %q", name))
+				continue
+			}
+
+			c.check(join(path, name), m[name], field.Type)
+		}
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			// Arrays of tables are merged into a single table when decoded, e.g. [[Matchers]]
+			if items, isMaps := toMaps(value); isMaps && value != nil {
+				for i, item := range items {
+					c.check(fmt.Sprintf("%s[%d]", path, i), item, t)
+				}
+				return
+			}
+
+			c.mistyped(path, "a table", value)
+			return
+		}
+
+		for _, name := range sortedKeys(m) {
+			c.check(join(path, name), m[name], t.Elem())
+		}
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			// TOML decodes arrays of tables as slices of maps
+			if maps, isMaps := value.([]map[string]interface{}); isMaps {
+				for _, item := range maps {
+					items = append(items, item)
+				}
+			} else {
+				c.mistyped(path, "an array", value)
+				return
+			}
+		}
+
+		for i, item := range items {
+			c.check(fmt.Sprintf("%s[%d]", path, i), item, t.Elem())
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			c.mistyped(path, "a string", value)
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			c.mistyped(path, "a boolean", value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := toNumber(value); !ok {
+			c.mistyped(path, "an integer", value)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := toNumber(value); !ok || n < 0 {
+			c.mistyped(path, "a positive integer", value)
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := toFloat(value); !ok {
+			c.mistyped(path, "a number", value)
+		}
+	}
+}
+
+func (c *checker) mistyped(path string, expected string, value interface{}) {
+	c.report(path, fmt.Sprintf("expected %s, got %s", expected, describe(value)))
+}
+
+// report adds a problem at the line of its path in the file.
+func (c *checker) report(path string, message string) {
+	c.problems = append(c.problems, &Problem{Line: c.positions[path], Path: path, Message: message})
+}
+
+// fieldByName finds a struct field the way the config is unmarshalled, ignoring the case.
+func fieldByName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if strings.EqualFold(t.Field(i).Name, name) {
+			return t.Field(i), true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, v == float64(int64(v))
+	}
+
+	return 0, false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	if v, ok := value.(float64); ok {
+		return v, true
+	}
+
+	return toNumber(value)
+}
+
+func describe(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nothing"
+	case string:
+		return fmt.Sprintf("string %q", value)
+	case bool:
+		return fmt.Sprintf("boolean %v", value)
+	case int, int64, uint64, float64:
+		return fmt.Sprintf("number %v", value)
+	case []interface{}, []map[string]interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "a table"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// normaliseYAML converts the maps decoded from YAML to maps with string keys.
+func normaliseYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprintf("%v", key)] = normaliseYAML(item)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = normaliseYAML(item)
+		}
+		return items
+	}
+
+	return value
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func join(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		format   string
+		content  string
+		expected []string
+	}{
+		{
+			scenario: "when toml config is valid",
+			format:   "toml",
+			content: `
+[Matchers]
+  ActiveUsers = "users.active = TRUE"
+
+[[Tables]]
+  Name = "users"
+  IgnoreData = false
+  [Tables.Filter]
+    Match = "ActiveUsers"
+    Limit = 100
+  [Tables.Anonymise]
+    email = "EmailAddress"
+  [[Tables.Relationships]]
+    ReferencedTable = "users"
+`,
+		},
+		{
+			scenario: "when toml config has an array of matchers",
+			format:   "toml",
+			content: `
+[[Matchers]]
+  ActiveUsers = "users.active = TRUE"
+
+[[Matchers]]
+  Limit = 10
+`,
+			expected: []string{
+				`line 6: Matchers[1].Limit: expected a string, got number 10`,
+			},
+		},
+		{
+			scenario: "when toml config has unknown and mistyped keys",
+			format:   "toml",
+			content: `
+[[Tables]]
+  Name = "users"
+  [Tables.Filters]
+    Limit = 100
+
+[[Tables]]
+  Name = "orders"
+  IgnoreData = "yes"
+  [[Tables.Relationship]]
+    ReferencedTable = "users"
+`,
+			expected: []string{
+				`line 4: Tables[0].Filters: unknown key "Filters"`,
+				`line 9: Tables[1].IgnoreData: expected a boolean, got string "yes"`,
+				`line 10: Tables[1].Relationship: unknown key "Relationship"`,
+			},
+		},
+		{
+			scenario: "when toml config has the same key in two tables and only the second is wrong",
+			format:   "toml",
+			content: `
+[[Tables]]
+  Name = "users"
+  # Limit = "all" the users
+  [Tables.Filter]
+    Limit = 100
+
+[[Tables]]
+  Name = "orders"
+  Description = "Limit = 10"
+  [Tables.Filter]
+    Limit = "ten"
+`,
+			expected: []string{
+				`line 10: Tables[1].Description: unknown key "Description"`,
+				`line 12: Tables[1].Filter.Limit: expected a positive integer, got string "ten"`,
+			},
+		},
+		{
+			scenario: "when yaml config has the same key in two tables and only the second is wrong",
+			format:   "yaml",
+			content: `
+Tables:
+- Name: users
+  # Limit: all the users
+  Filter:
+    Limit: 100
+- Name: orders
+  Filter:
+    Limit: ten
+`,
+			expected: []string{
+				`line 9: Tables[1].Filter.Limit: expected a positive integer, got string "ten"`,
+			},
+		},
+		{
+			scenario: "when json config has the same key in two tables and only the second is wrong",
+			format:   "json",
+			content: `{
+  "Tables": [
+    {"Name": "users", "Filter": {"Limit": 100}},
+    {
+      "Name": "orders",
+      "Filter": {"Limit": "ten"}
+    }
+  ]
+}`,
+			expected: []string{
+				`line 6: Tables[1].Filter.Limit: expected a positive integer, got string "ten"`,
+			},
+		},
+		{
+			scenario: "when yaml config has unknown and mistyped keys",
+			format:   "yaml",
+			content: `
+Tables:
+  - Name: users
+    Filter:
+      Limit: -1
+  - Name: orders
+    Filters:
+      Limit: 10
+`,
+			expected: []string{
+				`line 5: Tables[0].Filter.Limit: expected a positive integer, got number -1`,
+				`line 7: Tables[1].Filters: unknown key "Filters"`,
+			},
+		},
+		{
+			scenario: "when json config has unknown keys",
+			format:   "json",
+			content: `{
+  "Tables": [
+    {"Name": "users", "Anonymise": {"email": "EmailAddress"}},
+    {"Name": "orders", "Anonymize": {"email": "EmailAddress"}}
+  ]
+}`,
+			expected: []string{
+				`line 4: Tables[1].Anonymize: unknown key "Anonymize"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			problems, err := Check([]byte(test.content), test.format)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, problem := range problems {
+				messages = append(messages, problem.Error())
+			}
+			assert.Equal(t, test.expected, messages)
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := Schema()
+
+	properties := schema["properties"].(map[string]interface{})
+	assert.Contains(t, properties, "Tables")
+	assert.Contains(t, properties, "Anonymise")
+
+	table := properties["Tables"].(map[string]interface{})["items"].(map[string]interface{})
+	assert.Equal(t, false, table["additionalProperties"])
+	assert.Contains(t, table["properties"], "Relationships")
+}
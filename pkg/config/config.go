@@ -1,6 +1,15 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
 
 type (
 	// Spec represents the global app configuration.
@@ -8,8 +17,52 @@ type (
 		Matchers
 		Tables
 		Views
+		// Anonymise are anonymisation rules applied to the columns of every table.
+		Anonymise Rules
+		// Connections are the databases used when they are not given as flags.
+		Connections Connections
+		// Include are config files merged under this one, relative to it.
+		Include []string
+		// Profiles are named overrides of the config selected with --profile.
+		Profiles map[string]*Spec
+		// Vars are the default values of the :name parameters of the matchers.
+		Vars Vars
+		// Hooks are SQL scripts run against the target during a steal.
+		Hooks Hooks
+	}
+
+	// Hooks are SQL scripts run against the target at the steps of a steal.
+	Hooks struct {
+		// BeforeStructure runs before the structure is created.
+		BeforeStructure Hook
+		// BeforeData runs before the data of the tables is copied.
+		BeforeData Hook
+		// AfterData runs once the data of all the tables is copied.
+		AfterData Hook
+		// AfterTable runs once the data of a table is copied, keyed by table name.
+		AfterTable map[string]Hook
+	}
+
+	// Hook is an SQL script, given inline or as a file.
+	Hook struct {
+		// SQL is the script.
+		SQL string
+		// File is a file with the script, relative to the config file.
+		File string
 	}
 
+	// Connections are the databases to read from and write to.
+	Connections struct {
+		// From is the dsn of the database to read from.
+		From string
+		// To is the dsn of the database to write to.
+		To string
+	}
+
+	// Rules are anonymisation rules keyed by a column name glob (e.g. "*email*"),
+	// a table and column name glob (e.g. "*.phone") or a regular expression between slashes (e.g. "/^(tel|fax)$/").
+	Rules map[string]string
+
 	// Matchers are variables to store filter data,
 	// you can declare a filter once and reuse it among tables.
 	Matchers map[string]string
@@ -32,6 +85,33 @@ type (
 		Anonymise map[string]string
 		// Relationship is an collection of relationship definitions.
 		Relationships []*Relationship
+		// Incremental copies only the rows changed since the last steal.
+		Incremental Incremental
+		// WriteMode is how the rows are written into a table that already has data.
+		WriteMode WriteMode
+		// Transform changes the structure of the table on the target.
+		Transform Transform
+	}
+
+	// Transform changes the structure of a table on the target.
+	Transform struct {
+		// Rename is the name of the table on the target.
+		Rename string
+		// Columns renames the columns on the target, keyed by their source name.
+		Columns map[string]string
+		// DropIndexes are the indexes that are not created on the target.
+		DropIndexes []string
+		// Cast changes the type of the columns on the target, keyed by their source name.
+		Cast map[string]string
+	}
+
+	// WriteMode is how the rows are written into the target table.
+	WriteMode string
+
+	// Incremental defines how the changed rows of a table are found.
+	Incremental struct {
+		// Column is a column that increases when a row changes, e.g. updated_at.
+		Column string
 	}
 
 	// Filter represents the way you want to filter the results.
@@ -42,6 +122,18 @@ type (
 		Limit uint64
 		// Sorts is the sort condition for the table.
 		Sorts map[string]string
+		// Sample reads a random sample of the rows.
+		Sample Sample
+	}
+
+	// Sample is a reproducible random sample of the table rows.
+	Sample struct {
+		// Percent is the percentage of rows sampled.
+		Percent float64
+		// Rows is the number of rows sampled.
+		Rows uint64
+		// Seed selects another sample of the rows.
+		Seed int64
 	}
 
 	// Relationship represents the relationship between the table and referenced table.
@@ -54,9 +146,105 @@ type (
 		ReferencedTable string
 		// ReferencedKey is the referenced table primary key name.
 		ReferencedKey string
+		// Keys are the additional key pairs of a composite key.
+		Keys []*RelationshipKey
+		// JoinType is either INNER (the default) or LEFT.
+		JoinType string
+		// Alias is the name the referenced table is joined as, e.g. to join a table to itself.
+		Alias string
 	}
+
+	// RelationshipKey is a foreign key column and the referenced column.
+	RelationshipKey struct {
+		// ForeignKey is the table name foreign key.
+		ForeignKey string
+		// ReferencedKey is the referenced table key name.
+		ReferencedKey string
+	}
+)
+
+const (
+	// InnerJoin only reads the rows that have a referenced row.
+	InnerJoin = "INNER"
+	// LeftJoin reads all rows, with or without a referenced row.
+	LeftJoin = "LEFT"
+
+	// WriteAppend inserts the rows, failing on rows that already exist.
+	WriteAppend WriteMode = "append"
+	// WriteTruncate empties the table before inserting the rows.
+	WriteTruncate WriteMode = "truncate"
+	// WriteUpsert inserts the rows, updating the rows with the same key.
+	WriteUpsert WriteMode = "upsert"
+	// WriteSkipExisting inserts the rows, skipping the rows with the same key.
+	WriteSkipExisting WriteMode = "skip-existing"
 )
 
+// Validate checks the hooks have either SQL or an existing file.
+func (h Hooks) Validate() error {
+	hooks := map[string]Hook{
+		"BeforeStructure": h.BeforeStructure,
+		"BeforeData":      h.BeforeData,
+		"AfterData":       h.AfterData,
+	}
+	for table, hook := range h.AfterTable {
+		hooks["AfterTable."+table] = hook
+	}
+
+	for name, hook := range hooks {
+		if err := hook.Validate(); err != nil {
+			return fmt.Errorf("invalid %s hook: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+// IsZero returns true when no hook is configured.
+func (h Hooks) IsZero() bool {
+	return h.BeforeStructure == (Hook{}) && h.BeforeData == (Hook{}) && h.AfterData == (Hook{}) && len(h.AfterTable) == 0
+}
+
+// TableHook returns the hook run once the data of the table is copied.
+func (h Hooks) TableHook(name string) Hook {
+	// The keys of the config are case insensitive
+	for table, hook := range h.AfterTable {
+		if strings.EqualFold(table, name) {
+			return hook
+		}
+	}
+
+	return Hook{}
+}
+
+// Validate checks the hook has either SQL or an existing file.
+func (h Hook) Validate() error {
+	if h.SQL != "" && h.File != "" {
+		return errors.New("SQL and File cannot be used together")
+	}
+
+	if h.File != "" {
+		if _, err := os.Stat(h.File); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Script returns the SQL of the hook, empty when there is none.
+func (h Hook) Script() (string, error) {
+	if h.File == "" {
+		return h.SQL, nil
+	}
+
+	content, err := ioutil.ReadFile(h.File)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
 // FindByName find a table by its name.
 func (t Tables) FindByName(name string) (*Table, error) {
 	for _, table := range t {
@@ -67,3 +255,209 @@ func (t Tables) FindByName(name string) (*Table, error) {
 
 	return nil, errors.New("table not found")
 }
+
+// IsZero checks if no sampling is configured.
+func (s Sample) IsZero() bool {
+	return s.Percent == 0 && s.Rows == 0
+}
+
+// Validate checks that the sample is either a percentage between 0 and 100 or a number of rows.
+func (s Sample) Validate() error {
+	if s.Percent < 0 || s.Percent > 100 {
+		return errors.New("sample percent must be between 0 and 100")
+	}
+
+	if s.Percent > 0 && s.Rows > 0 {
+		return errors.New("sample can either be a percent or a number of rows")
+	}
+
+	return nil
+}
+
+// KeyPairs returns all the key pairs of the relationship, starting with ForeignKey and ReferencedKey.
+func (r *Relationship) KeyPairs() []*RelationshipKey {
+	var keys []*RelationshipKey
+	if r.ForeignKey != "" || r.ReferencedKey != "" {
+		keys = append(keys, &RelationshipKey{ForeignKey: r.ForeignKey, ReferencedKey: r.ReferencedKey})
+	}
+
+	return append(keys, r.Keys...)
+}
+
+// Name returns the name the referenced table is joined as.
+func (r *Relationship) Name() string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+
+	return r.ReferencedTable
+}
+
+// Validate checks that the relationship has a referenced table, keys and a known join type.
+func (r *Relationship) Validate() error {
+	if r.ReferencedTable == "" {
+		return errors.New("relationship has no referenced table")
+	}
+
+	keys := r.KeyPairs()
+	if len(keys) == 0 {
+		return errors.New("relationship has no keys")
+	}
+
+	for _, key := range keys {
+		if key.ForeignKey == "" || key.ReferencedKey == "" {
+			return errors.New("relationship keys need both a foreign key and a referenced key")
+		}
+	}
+
+	switch strings.ToUpper(r.JoinType) {
+	case "", InnerJoin, LeftJoin:
+		return nil
+	}
+
+	return fmt.Errorf("unknown join type %q, expected %s or %s", r.JoinType, InnerJoin, LeftJoin)
+}
+
+// IsZero checks if the transform changes nothing.
+func (t Transform) IsZero() bool {
+	return t.Rename == "" && len(t.Columns) == 0 && len(t.DropIndexes) == 0 && len(t.Cast) == 0
+}
+
+// TableName returns the name of the table on the target.
+func (t Transform) TableName(name string) string {
+	if t.Rename != "" {
+		return t.Rename
+	}
+
+	return name
+}
+
+// ColumnName returns the name of the column on the target.
+func (t Transform) ColumnName(name string) string {
+	if renamed, ok := t.Columns[name]; ok && renamed != "" {
+		return renamed
+	}
+
+	return name
+}
+
+// Validate checks that the write mode is known, empty is append.
+func (m WriteMode) Validate() error {
+	switch m {
+	case "", WriteAppend, WriteTruncate, WriteUpsert, WriteSkipExisting:
+		return nil
+	}
+
+	return fmt.Errorf("unknown write mode %q, expected %s, %s, %s or %s", m, WriteAppend, WriteTruncate, WriteUpsert, WriteSkipExisting)
+}
+
+// Find returns the rule matching the table column. The most specific pattern wins: exact table and column
+// names first, then exact column names, then globs with the most literal characters, then regular expressions.
+func (r Rules) Find(table string, column string) (string, bool) {
+	patterns := make([]string, 0, len(r))
+	for pattern := range r {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		rankI, literalsI := patternSpecificity(patterns[i])
+		rankJ, literalsJ := patternSpecificity(patterns[j])
+		if rankI != rankJ {
+			return rankI < rankJ
+		}
+		if literalsI != literalsJ {
+			return literalsI > literalsJ
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	for _, pattern := range patterns {
+		if matchColumn(pattern, table, column) {
+			return r[pattern], true
+		}
+	}
+
+	return "", false
+}
+
+// Merge returns the rules of the table columns, the table rules take precedence over the global ones.
+func (r Rules) Merge(table *Table, columns []string) map[string]string {
+	rules := make(map[string]string, len(table.Anonymise))
+	for _, column := range columns {
+		if rule, ok := r.Find(table.Name, column); ok {
+			rules[column] = rule
+		}
+	}
+
+	for column, rule := range table.Anonymise {
+		rules[column] = rule
+	}
+
+	return rules
+}
+
+// ValidatePattern checks that a rule pattern is a valid glob or regular expression.
+func ValidatePattern(pattern string) error {
+	if isRegexPattern(pattern) {
+		_, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		return err
+	}
+
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+// patternSpecificity returns the rank of the kind of a pattern, lower is more specific,
+// and the number of literal characters of a glob.
+func patternSpecificity(pattern string) (rank int, literals int) {
+	if isRegexPattern(pattern) {
+		return 3, 0
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?':
+			rank = 2
+		case '[':
+			rank = 2
+			if end := strings.IndexByte(pattern[i:], ']'); end > 0 {
+				i += end
+			}
+			literals++
+		case '\\':
+			i++
+			literals++
+		default:
+			literals++
+		}
+	}
+
+	if rank == 2 {
+		return rank, literals
+	}
+
+	if strings.Contains(pattern, ".") {
+		return 0, literals
+	}
+
+	return 1, literals
+}
+
+func isRegexPattern(pattern string) bool {
+	return len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+func matchColumn(pattern string, table string, column string) bool {
+	name := strings.ToLower(column)
+
+	if isRegexPattern(pattern) {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		return err == nil && re.MatchString(name)
+	}
+
+	if strings.Contains(pattern, ".") {
+		name = strings.ToLower(table) + "." + name
+	}
+
+	matched, err := path.Match(strings.ToLower(pattern), name)
+	return err == nil && matched
+}
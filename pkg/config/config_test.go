@@ -0,0 +1,175 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesFind(t *testing.T) {
+	t.Parallel()
+
+	rules := Rules{
+		"*email*":       "EmailAddress",
+		"*.phone":       "Phone",
+		"users.name":    "FullName",
+		"/^(tel|fax)$/": "literal:0",
+	}
+
+	tests := []struct {
+		scenario string
+		table    string
+		column   string
+		expected string
+		found    bool
+	}{
+		{scenario: "when column glob matches", table: "users", column: "contact_email", expected: "EmailAddress", found: true},
+		{scenario: "when table and column glob matches", table: "orders", column: "phone", expected: "Phone", found: true},
+		{scenario: "when table and column name matches", table: "users", column: "name", expected: "FullName", found: true},
+		{scenario: "when table does not match", table: "products", column: "name"},
+		{scenario: "when regex matches", table: "users", column: "FAX", expected: "literal:0", found: true},
+		{scenario: "when regex does not match", table: "users", column: "telephone_number"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			rule, found := rules.Find(test.table, test.column)
+			assert.Equal(t, test.found, found)
+			assert.Equal(t, test.expected, rule)
+		})
+	}
+}
+
+func TestRulesFindSpecificity(t *testing.T) {
+	t.Parallel()
+
+	rules := Rules{
+		"*email*":        "EmailAddress",
+		"*_email":        "literal:glob",
+		"users.email":    "keep",
+		"email":          "literal:column",
+		"/email/":        "literal:regex",
+		"*.backup_email": "literal:table glob",
+	}
+
+	tests := []struct {
+		scenario string
+		table    string
+		column   string
+		expected string
+	}{
+		{scenario: "when the table and column rule overrides a glob", table: "users", column: "email", expected: "keep"},
+		{scenario: "when the column rule overrides a glob", table: "orders", column: "email", expected: "literal:column"},
+		{scenario: "when the glob with more literal characters wins", table: "users", column: "backup_email", expected: "literal:table glob"},
+		{scenario: "when a glob wins over a regex", table: "users", column: "contact_email", expected: "literal:glob"},
+		{scenario: "when only the regex and the widest glob match", table: "users", column: "emails", expected: "EmailAddress"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			rule, found := rules.Find(test.table, test.column)
+			assert.True(t, found)
+			assert.Equal(t, test.expected, rule)
+		})
+	}
+}
+
+func TestRelationshipValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario     string
+		relationship Relationship
+		valid        bool
+	}{
+		{
+			scenario:     "when the relationship has a key",
+			relationship: Relationship{ReferencedTable: "users", ForeignKey: "user_id", ReferencedKey: "id"},
+			valid:        true,
+		},
+		{
+			scenario: "when the relationship has a composite key",
+			relationship: Relationship{ReferencedTable: "shops", JoinType: "left", Keys: []*RelationshipKey{
+				{ForeignKey: "shop_id", ReferencedKey: "id"},
+				{ForeignKey: "region", ReferencedKey: "region"},
+			}},
+			valid: true,
+		},
+		{
+			scenario:     "when the relationship has no keys",
+			relationship: Relationship{ReferencedTable: "users"},
+		},
+		{
+			scenario:     "when a key is incomplete",
+			relationship: Relationship{ReferencedTable: "users", ForeignKey: "user_id"},
+		},
+		{
+			scenario:     "when the join type is unknown",
+			relationship: Relationship{ReferencedTable: "users", ForeignKey: "user_id", ReferencedKey: "id", JoinType: "CROSS"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			err := test.relationship.Validate()
+			assert.Equal(t, test.valid, err == nil)
+		})
+	}
+}
+
+func TestWriteModeValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []WriteMode{"", WriteAppend, WriteTruncate, WriteUpsert, WriteSkipExisting} {
+		assert.NoError(t, mode.Validate(), string(mode))
+	}
+
+	assert.Error(t, WriteMode("replace").Validate())
+}
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+
+	transform := Transform{Rename: "users", Columns: map[string]string{"usr_name": "name"}}
+	assert.False(t, transform.IsZero())
+	assert.Equal(t, "users", transform.TableName("tbl_user"))
+	assert.Equal(t, "name", transform.ColumnName("usr_name"))
+	assert.Equal(t, "id", transform.ColumnName("id"))
+
+	assert.True(t, Transform{}.IsZero())
+	assert.Equal(t, "tbl_user", Transform{}.TableName("tbl_user"))
+}
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	file, err := ioutil.TempFile("", "klepto-hook")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString("GRANT SELECT ON users TO app;")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	hooks := Hooks{
+		BeforeStructure: Hook{SQL: "CREATE EXTENSION IF NOT EXISTS citext;"},
+		AfterTable:      map[string]Hook{"users": {File: file.Name()}},
+	}
+	assert.False(t, hooks.IsZero())
+	assert.NoError(t, hooks.Validate())
+
+	script, err := hooks.TableHook("Users").Script()
+	assert.NoError(t, err)
+	assert.Equal(t, "GRANT SELECT ON users TO app;", script)
+
+	script, err = hooks.TableHook("orders").Script()
+	assert.NoError(t, err)
+	assert.Empty(t, script)
+
+	assert.True(t, Hooks{}.IsZero())
+	assert.Error(t, Hooks{AfterData: Hook{SQL: "SELECT 1;", File: file.Name()}}.Validate())
+	assert.Error(t, Hooks{BeforeData: Hook{File: file.Name() + ".missing"}}.Validate())
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// filePrefix replaces a value with the content of a file e.g. file:/run/secrets/dsn
+const filePrefix = "file:"
+
+var envRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Interpolate replaces the ${ENV_VAR} references in the connections of the spec with the environment variables
+// and the values starting with file: with the content of the file.
+// Only the connections hold secrets, the SQL of matchers, rules and hooks is left as is as it may contain ${.
+func Interpolate(spec *Spec) error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"From", &spec.Connections.From},
+		{"To", &spec.Connections.To},
+	}
+
+	for _, field := range fields {
+		value, err := interpolateString(*field.value)
+		if err != nil {
+			return errors.Wrapf(err, "Connections.%s", field.name)
+		}
+
+		*field.value = value
+	}
+
+	return nil
+}
+
+func interpolateString(value string) (string, error) {
+	var missing []string
+	value = envRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRegex.FindStringSubmatch(ref)[1]
+
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+
+		return env
+	})
+
+	if len(missing) > 0 {
+		return "", errors.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+
+	if !strings.HasPrefix(value, filePrefix) {
+		return value, nil
+	}
+
+	content, err := ioutil.ReadFile(strings.TrimPrefix(value, filePrefix))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read file")
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
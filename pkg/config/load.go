@@ -0,0 +1,222 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	includeKey  = "include"
+	profilesKey = "profiles"
+	tablesKey   = "tables"
+	nameKey     = "name"
+	hooksKey    = "hooks"
+	fileKey     = "file"
+)
+
+// Load reads a config file merging the files it includes and the selected profile.
+// Every file is checked for unknown or mistyped keys, problems are returned without loading the config.
+func Load(filename string, profile string) (*Spec, []*Problem, error) {
+	settings, problems, err := loadSettings(filename, make(map[string]bool))
+	if err != nil || len(problems) > 0 {
+		return nil, problems, err
+	}
+
+	if profile != "" {
+		profiles, _ := lookup(settings, profilesKey).(map[string]interface{})
+
+		overrides, ok := lookup(profiles, profile).(map[string]interface{})
+		if !ok {
+			return nil, nil, errors.Errorf("profile %q is not defined", profile)
+		}
+
+		settings = mergeSettings(settings, overrides)
+	}
+
+	delete(settings, includeKey)
+	delete(settings, profilesKey)
+
+	spec := new(Spec)
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{WeaklyTypedInput: true, Result: spec})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create config decoder")
+	}
+
+	if err := decoder.Decode(settings); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode config")
+	}
+
+	return spec, nil, nil
+}
+
+// loadSettings reads a config file on top of the files it includes, which paths are relative to it.
+func loadSettings(filename string, loading map[string]bool) (map[string]interface{}, []*Problem, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to resolve %s", filename)
+	}
+
+	if loading[path] {
+		return nil, nil, errors.Errorf("%s is included recursively", filename)
+	}
+	loading[path] = true
+	defer delete(loading, path)
+
+	problems, err := CheckFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, problem := range problems {
+		problem.File = filename
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read %s", filename)
+	}
+
+	settings := make(map[string]interface{})
+	for _, include := range v.GetStringSlice(includeKey) {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		included, includedProblems, err := loadSettings(include, loading)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		problems = append(problems, includedProblems...)
+		settings = mergeSettings(settings, included)
+	}
+
+	own := v.AllSettings()
+	resolveHookFiles(own, filepath.Dir(path))
+
+	return mergeSettings(settings, own), problems, nil
+}
+
+// resolveHookFiles makes the files of the hooks, and of the hooks of the profiles, relative to the config file.
+func resolveHookFiles(settings map[string]interface{}, dir string) {
+	if profiles, ok := lookup(settings, profilesKey).(map[string]interface{}); ok {
+		for _, profile := range profiles {
+			if profile, ok := profile.(map[string]interface{}); ok {
+				resolveHookFiles(profile, dir)
+			}
+		}
+	}
+
+	if hooks, ok := lookup(settings, hooksKey).(map[string]interface{}); ok {
+		resolveFiles(hooks, dir)
+	}
+}
+
+func resolveFiles(settings map[string]interface{}, dir string) {
+	for key, value := range settings {
+		if nested, ok := value.(map[string]interface{}); ok {
+			resolveFiles(nested, dir)
+			continue
+		}
+
+		if file, ok := value.(string); ok && strings.EqualFold(key, fileKey) && file != "" && !filepath.IsAbs(file) {
+			settings[key] = filepath.Join(dir, file)
+		}
+	}
+}
+
+// mergeSettings deep merges the overrides into the settings, tables are merged by name
+// while other lists are replaced.
+func mergeSettings(settings map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(settings)+len(overrides))
+	for key, value := range settings {
+		merged[strings.ToLower(key)] = value
+	}
+
+	for key, value := range overrides {
+		key = strings.ToLower(key)
+
+		switch current := merged[key].(type) {
+		case map[string]interface{}:
+			if override, ok := value.(map[string]interface{}); ok {
+				merged[key] = mergeSettings(current, override)
+				continue
+			}
+		default:
+			if key == tablesKey {
+				currentTables, ok := toMaps(current)
+				overrideTables, isTables := toMaps(value)
+				if ok && isTables {
+					merged[key] = mergeTables(currentTables, overrideTables)
+					continue
+				}
+			}
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
+
+func mergeTables(tables []map[string]interface{}, overrides []map[string]interface{}) []interface{} {
+	merged := make([]interface{}, 0, len(tables)+len(overrides))
+	for _, table := range tables {
+		merged = append(merged, table)
+	}
+
+	for _, override := range overrides {
+		found := false
+		for i, table := range merged {
+			table := table.(map[string]interface{})
+			if lookup(table, nameKey) == lookup(override, nameKey) {
+				merged[i] = mergeSettings(table, override)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			merged = append(merged, override)
+		}
+	}
+
+	return merged
+}
+
+// lookup gets a value ignoring the case of the key.
+func lookup(settings map[string]interface{}, key string) interface{} {
+	for k, value := range settings {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func toMaps(value interface{}) ([]map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case []map[string]interface{}:
+		return v, true
+	case []interface{}:
+		maps := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			m, ok := normaliseYAML(item).(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			maps = append(maps, m)
+		}
+		return maps, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klepto")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "base.toml", `
+[Matchers]
+  Active = "users.active = TRUE"
+
+[[Tables]]
+  Name = "users"
+  [Tables.Filter]
+    Match = "Active"
+    Limit = 100
+  [Tables.Anonymise]
+    email = "EmailAddress"
+
+[[Tables]]
+  Name = "logs"
+  IgnoreData = true
+
+[Hooks]
+  [Hooks.BeforeStructure]
+    SQL = "CREATE EXTENSION IF NOT EXISTS citext;"
+  [Hooks.AfterTable.users]
+    File = "hooks/users.sql"
+`)
+	writeFile(t, dir, ".klepto.toml", `
+Include = ["base.toml"]
+
+[Connections]
+  To = "postgres://localhost/qa"
+
+[[Tables]]
+  Name = "users"
+  [Tables.Anonymise]
+    phone = "Phone"
+
+[Profiles.local]
+  [Profiles.local.Connections]
+    To = "postgres://localhost/dev"
+  [[Profiles.local.Tables]]
+    Name = "users"
+    [Profiles.local.Tables.Filter]
+      Limit = 10
+`)
+
+	tests := []struct {
+		scenario string
+		profile  string
+		limit    uint64
+		to       string
+	}{
+		{scenario: "when no profile is selected", limit: 100, to: "postgres://localhost/qa"},
+		{scenario: "when a profile is selected", profile: "local", limit: 10, to: "postgres://localhost/dev"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			spec, problems, err := Load(filepath.Join(dir, ".klepto.toml"), test.profile)
+			require.NoError(t, err)
+			require.Empty(t, problems)
+
+			assert.Equal(t, "users.active = TRUE", spec.Matchers["active"])
+			assert.Equal(t, test.to, spec.Connections.To)
+			require.Len(t, spec.Tables, 2)
+
+			users, err := spec.Tables.FindByName("users")
+			require.NoError(t, err)
+			assert.Equal(t, "Active", users.Filter.Match)
+			assert.Equal(t, test.limit, users.Filter.Limit)
+			assert.Equal(t, map[string]string{"email": "EmailAddress", "phone": "Phone"}, users.Anonymise)
+
+			logs, err := spec.Tables.FindByName("logs")
+			require.NoError(t, err)
+			assert.True(t, logs.IgnoreData)
+
+			assert.Equal(t, "CREATE EXTENSION IF NOT EXISTS citext;", spec.Hooks.BeforeStructure.SQL)
+			assert.Equal(t, filepath.Join(dir, "hooks", "users.sql"), spec.Hooks.TableHook("users").File)
+		})
+	}
+
+	_, _, err = Load(filepath.Join(dir, ".klepto.toml"), "missing")
+	assert.Error(t, err)
+}
+
+func TestLoadExamples(t *testing.T) {
+	examples, err := filepath.Glob(filepath.Join("..", "..", "examples", "*.toml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, examples)
+
+	for _, example := range examples {
+		t.Run(filepath.Base(example), func(t *testing.T) {
+			spec, problems, err := Load(example, "")
+			require.NoError(t, err)
+			assert.Empty(t, problems)
+			assert.NotEmpty(t, spec.Tables)
+		})
+	}
+
+	spec, _, err := Load(filepath.Join("..", "..", "examples", "user-orders-using-matchers.toml"), "")
+	require.NoError(t, err)
+	assert.Contains(t, spec.Matchers, "Latest100ActiveUsers")
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+}
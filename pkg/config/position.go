@@ -0,0 +1,260 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type (
+	// positions maps the path of each key of a config file, e.g. Tables[1].Filter.Limit, to its line.
+	positions map[string]int
+
+	// yamlEntry is an open mapping or sequence item while reading a YAML file.
+	yamlEntry struct {
+		indent int
+		path   string
+		item   bool
+	}
+)
+
+// locate returns the positions of the keys of a config file in the given format.
+func locate(content []byte, format string) positions {
+	switch strings.ToLower(format) {
+	case "toml":
+		return tomlPositions(strings.Split(string(content), "\n"))
+	case "yaml", "yml":
+		return yamlPositions(strings.Split(string(content), "\n"))
+	case "json":
+		return jsonPositions(content)
+	}
+
+	return positions{}
+}
+
+func (p positions) add(path string, line int) {
+	if _, ok := p[path]; !ok {
+		p[path] = line
+	}
+}
+
+// tomlPositions follows the table headers, numbering the arrays of tables the way they are decoded.
+func tomlPositions(lines []string) positions {
+	p := make(positions)
+	items := make(map[string]int)
+	table := ""
+	multiline := ""
+
+	for i, line := range lines {
+		if multiline != "" {
+			if strings.Contains(line, multiline) {
+				multiline = ""
+			}
+			continue
+		}
+
+		line, _ = cutUnquoted(line, '#')
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "[["):
+			path := resolveTOML(items, "", splitKey(strings.TrimSuffix(strings.TrimPrefix(line, "[["), "]]")))
+			p.add(path, i+1)
+			table = fmt.Sprintf("%s[%d]", path, items[path])
+			items[path]++
+			p.add(table, i+1)
+		case strings.HasPrefix(line, "["):
+			table = resolveTOML(items, "", splitKey(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")))
+			p.add(table, i+1)
+		default:
+			key, ok := cutUnquoted(line, '=')
+			if !ok {
+				continue
+			}
+
+			path := table
+			for _, part := range splitKey(key) {
+				path = join(path, part)
+				p.add(path, i+1)
+			}
+
+			value := strings.TrimSpace(line[len(key)+1:])
+			for _, quotes := range []string{`"""`, `'''`} {
+				if strings.HasPrefix(value, quotes) && !strings.Contains(value[len(quotes):], quotes) {
+					multiline = quotes
+				}
+			}
+		}
+	}
+
+	return p
+}
+
+// resolveTOML joins the parts of a header, an array of tables in the middle refers to its last item.
+func resolveTOML(items map[string]int, path string, parts []string) string {
+	for i, part := range parts {
+		path = join(path, part)
+		if n := items[path]; n > 0 && i < len(parts)-1 {
+			path = fmt.Sprintf("%s[%d]", path, n-1)
+		}
+	}
+
+	return path
+}
+
+// yamlPositions follows the indentation of the block mappings and sequences.
+func yamlPositions(lines []string) positions {
+	p := make(positions)
+	items := make(map[string]int)
+	var (
+		stack  []yamlEntry
+		scalar = -1
+	)
+
+	for i, line := range lines {
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+		content, _ = cutUnquoted(content, '#')
+		content = strings.TrimSpace(content)
+
+		if content == "" || content == "---" {
+			continue
+		}
+		// Lines of a block scalar are values
+		if scalar >= 0 {
+			if indent > scalar {
+				continue
+			}
+			scalar = -1
+		}
+
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			for len(stack) > 0 && (stack[len(stack)-1].indent > indent || stack[len(stack)-1].indent == indent && stack[len(stack)-1].item) {
+				stack = stack[:len(stack)-1]
+			}
+
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1].path
+			}
+
+			path := fmt.Sprintf("%s[%d]", parent, items[parent])
+			items[parent]++
+			p.add(path, i+1)
+			stack = append(stack, yamlEntry{indent: indent, path: path, item: true})
+
+			rest := strings.TrimLeft(content[1:], " ")
+			indent += len(content) - len(rest)
+			content = rest
+		}
+
+		key, ok := cutUnquoted(content, ':')
+		if !ok || (len(content) > len(key)+1 && content[len(key)+1] != ' ') {
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		path := strings.Trim(strings.TrimSpace(key), `"'`)
+		if len(stack) > 0 {
+			path = join(stack[len(stack)-1].path, path)
+		}
+		p.add(path, i+1)
+		stack = append(stack, yamlEntry{indent: indent, path: path})
+
+		if value := strings.TrimSpace(content[len(key)+1:]); strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			scalar = indent
+		}
+	}
+
+	return p
+}
+
+// jsonPositions walks the tokens of the file, the decoder offsets give the lines.
+func jsonPositions(content []byte) positions {
+	p := make(positions)
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	line := func() int {
+		return bytes.Count(content[:decoder.InputOffset()], []byte("\n")) + 1
+	}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			p.add(path, line())
+		}
+
+		switch token {
+		case json.Delim('{'):
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+
+				name := fmt.Sprintf("%v", key)
+				p.add(join(path, name), line())
+				if err := walk(join(path, name)); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+		case json.Delim('['):
+			for i := 0; decoder.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+		}
+
+		return err
+	}
+
+	// The content was decoded before, an error only leaves the remaining keys without a line
+	_ = walk("")
+
+	return p
+}
+
+// cutUnquoted returns the text before the first separator found outside of quotes.
+func cutUnquoted(s string, separator byte) (string, bool) {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' && quote == '"' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == separator:
+			return s[:i], true
+		}
+	}
+
+	return s, false
+}
+
+// splitKey splits a dotted TOML key, e.g. Tables."user.email" gives Tables and user.email.
+func splitKey(key string) []string {
+	var parts []string
+	for {
+		part, ok := cutUnquoted(key, '.')
+		parts = append(parts, strings.Trim(strings.TrimSpace(part), `"'`))
+		if !ok {
+			return parts
+		}
+		key = key[len(part)+1:]
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+)
+
+// schemaURL is the JSON Schema draft the generated schema conforms to.
+const schemaURL = "http://json-schema.org/draft-07/schema#"
+
+// Schema returns the JSON Schema of the config file, generated from the Spec type.
+func Schema() map[string]interface{} {
+	schema := schemaOf(reflect.TypeOf(Spec{}), true)
+	schema["$schema"] = schemaURL
+	schema["title"] = "Klepto configuration"
+
+	return schema
+}
+
+// schemaOf returns the schema of a type, nested specs (profiles) refer to the root schema.
+func schemaOf(t reflect.Type, root bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(Spec{}) && !root {
+		return map[string]interface{}{"$ref": "#"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			properties[t.Field(i).Name] = schemaOf(t.Field(i).Type, false)
+		}
+
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		object := map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaOf(t.Elem(), false),
+		}
+
+		// Arrays of tables are merged into a single table when decoded, e.g. [[Matchers]]
+		return map[string]interface{}{
+			"anyOf": []interface{}{object, map[string]interface{}{"type": "array", "items": object}},
+		}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaOf(t.Elem(), false),
+		}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+
+	return map[string]interface{}{"type": "string"}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+)
+
+// VarsEnvPrefix is the prefix of the environment variables setting matcher parameters, e.g. KLEPTO_VAR_SINCE.
+const VarsEnvPrefix = "KLEPTO_VAR_"
+
+// Vars are the values of the :name parameters used in matchers.
+type Vars map[string]string
+
+// EnvVars returns the parameters set in the environment with the VarsEnvPrefix.
+func EnvVars() Vars {
+	vars := make(Vars)
+	for _, env := range os.Environ() {
+		pair := strings.SplitN(env, "=", 2)
+		if len(pair) == 2 && strings.HasPrefix(pair[0], VarsEnvPrefix) {
+			vars[strings.ToLower(strings.TrimPrefix(pair[0], VarsEnvPrefix))] = pair[1]
+		}
+	}
+
+	return vars
+}
+
+// Lookup returns the value of the parameter, ignoring the case of its name.
+func (v Vars) Lookup(name string) (string, bool) {
+	if value, ok := v[name]; ok {
+		return value, true
+	}
+
+	for key, value := range v {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// BindVars replaces the :name parameters of a SQL condition with the placeholder of their
+// position and returns their values in order. Quoted strings, identifiers, comments and
+// :: casts are left untouched. Backslashes escape quotes only in the mysql dialect.
+func BindVars(condition string, dialect string, vars Vars, placeholder func(position int) string) (string, []interface{}, error) {
+	var (
+		b       strings.Builder
+		args    []interface{}
+		quote   rune
+		comment bool
+		block   bool
+	)
+
+	runes := []rune(condition)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+			}
+		case block:
+			if r == '*' && i+1 < len(runes) && runes[i+1] == '/' {
+				b.WriteRune(r)
+				i++
+				r = runes[i]
+				block = false
+			}
+		case quote != 0:
+			escaped := r == '\\' && dialect == database.MySQL && quote != '`'
+			doubled := r == quote && i+1 < len(runes) && runes[i+1] == quote
+			if (escaped || doubled) && i+1 < len(runes) {
+				b.WriteRune(r)
+				i++
+				r = runes[i]
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			comment = true
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			b.WriteRune(r)
+			i++
+			r = runes[i]
+			block = true
+		case r == ':' && i+1 < len(runes) && runes[i+1] == ':':
+			b.WriteString("::")
+			i++
+			continue
+		case r == ':' && i+1 < len(runes) && isVarStart(runes[i+1]):
+			j := i + 1
+			for j < len(runes) && isVarPart(runes[j]) {
+				j++
+			}
+
+			name := string(runes[i+1 : j])
+			value, ok := vars.Lookup(name)
+			if !ok {
+				return "", nil, errors.Errorf("parameter :%s is not set, use --var %s=value or %s%s", name, name, VarsEnvPrefix, strings.ToUpper(name))
+			}
+
+			args = append(args, value)
+			b.WriteString(placeholder(len(args)))
+			i = j - 1
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String(), args, nil
+}
+
+func isVarStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isVarPart(r rune) bool {
+	return isVarStart(r) || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindVars(t *testing.T) {
+	t.Parallel()
+
+	vars := Vars{"since": "2024-01-01", "country": "DE"}
+	question := func(int) string { return "?" }
+	dollar := func(position int) string { return fmt.Sprintf("$%d", position) }
+
+	tests := []struct {
+		scenario    string
+		condition   string
+		placeholder func(int) string
+		dialect     string
+		expected    string
+		args        []interface{}
+		err         bool
+	}{
+		{
+			scenario:  "when the condition has no parameters",
+			condition: "users.active = TRUE",
+			expected:  "users.active = TRUE",
+		},
+		{
+			scenario:  "when the condition has parameters",
+			condition: "created_at > :since AND country = :country",
+			expected:  "created_at > ? AND country = ?",
+			args:      []interface{}{"2024-01-01", "DE"},
+		},
+		{
+			scenario:  "when the parameter case differs",
+			condition: "country = :Country",
+			expected:  "country = ?",
+			args:      []interface{}{"DE"},
+		},
+		{
+			scenario:  "when the condition has casts and quoted colons",
+			condition: "created_at::date > :since AND note <> ':country' AND \"a:b\" = 1",
+			expected:  "created_at::date > ? AND note <> ':country' AND \"a:b\" = 1",
+			args:      []interface{}{"2024-01-01"},
+		},
+		{
+			scenario:    "when the placeholders are numbered",
+			condition:   "created_at > :since AND country = :country",
+			placeholder: dollar,
+			expected:    "created_at > $1 AND country = $2",
+			args:        []interface{}{"2024-01-01", "DE"},
+		},
+		{
+			scenario:    "when the condition has question marks",
+			condition:   "tags ? 'vip' AND note <> '?' AND country = :country",
+			placeholder: dollar,
+			expected:    "tags ? 'vip' AND note <> '?' AND country = $1",
+			args:        []interface{}{"DE"},
+		},
+		{
+			scenario:  "when a mysql quoted string has escaped quotes",
+			condition: `note <> 'it\'s :country' AND country = :country`,
+			dialect:   database.MySQL,
+			expected:  `note <> 'it\'s :country' AND country = ?`,
+			args:      []interface{}{"DE"},
+		},
+		{
+			scenario:    "when a postgres quoted string ends with a backslash",
+			condition:   `path = 'C:\' AND country = :country`,
+			placeholder: dollar,
+			dialect:     database.PostgreSQL,
+			expected:    `path = 'C:\' AND country = $1`,
+			args:        []interface{}{"DE"},
+		},
+		{
+			scenario:  "when a quoted string has doubled quotes",
+			condition: "note <> 'it''s :country' AND country = :country",
+			expected:  "note <> 'it''s :country' AND country = ?",
+			args:      []interface{}{"DE"},
+		},
+		{
+			scenario:    "when a postgres quoted string has doubled quotes",
+			condition:   "note <> 'it''s :country' AND country = :country",
+			placeholder: dollar,
+			dialect:     database.PostgreSQL,
+			expected:    "note <> 'it''s :country' AND country = $1",
+			args:        []interface{}{"DE"},
+		},
+		{
+			scenario:  "when the condition has block comments",
+			condition: "country = :country /* 'since :since */ AND created_at > :since",
+			expected:  "country = ? /* 'since :since */ AND created_at > ?",
+			args:      []interface{}{"DE", "2024-01-01"},
+		},
+		{
+			scenario:  "when the condition has comments",
+			condition: "country = :country -- 'since :since\nAND created_at > :since",
+			expected:  "country = ? -- 'since :since\nAND created_at > ?",
+			args:      []interface{}{"DE", "2024-01-01"},
+		},
+		{
+			scenario:  "when a parameter is not set",
+			condition: "id = :missing",
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			placeholder := test.placeholder
+			if placeholder == nil {
+				placeholder = question
+			}
+
+			dialect := test.dialect
+			if dialect == "" {
+				dialect = database.MySQL
+			}
+
+			condition, args, err := BindVars(test.condition, dialect, vars, placeholder)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, condition)
+			assert.Equal(t, test.args, args)
+		})
+	}
+}
+
+func TestEnvVars(t *testing.T) {
+	os.Setenv("KLEPTO_VAR_SINCE", "2024-01-01")
+	defer os.Unsetenv("KLEPTO_VAR_SINCE")
+
+	value, ok := EnvVars().Lookup("since")
+	assert.True(t, ok)
+	assert.Equal(t, "2024-01-01", value)
+}
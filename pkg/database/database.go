@@ -3,4 +3,36 @@ package database
 type (
 	// Row is the database column row.
 	Row map[string]interface{}
+
+	// ColumnType holds the type information of a table column.
+	ColumnType struct {
+		// Name is the column name.
+		Name string
+		// DatabaseType is the database type name, e.g. VARCHAR, INT or UUID.
+		DatabaseType string
+		// Length is the maximum length of variable length types, 0 when unbounded or unknown.
+		Length int64
+	}
+
+	// ForeignKey is a foreign key constraint of a table.
+	ForeignKey struct {
+		// Name is the constraint name.
+		Name string
+		// Columns are the columns of the table holding the key.
+		Columns []string
+		// ReferencedTable is the table the key refers to.
+		ReferencedTable string
+		// ReferencedColumns are the referenced columns, in the order of Columns.
+		ReferencedColumns []string
+	}
+
+	// Structure is the SQL creating the database tables, split in sections like pg_dump does.
+	Structure struct {
+		// PreData creates the tables, without their indexes and constraints.
+		PreData string
+		// PostData are the statements creating the indexes, constraints and triggers, keyed by table.
+		PostData map[string][]string
+		// ForeignKeys are the foreign keys and the statements of no table, created after the post-data of every table.
+		ForeignKeys []string
+	}
 )
@@ -0,0 +1,171 @@
+package database
+
+const (
+	// MySQL is the dialect of MySQL databases.
+	MySQL = "mysql"
+	// PostgreSQL is the dialect of PostgreSQL databases.
+	PostgreSQL = "postgres"
+)
+
+const (
+	// TypeBoolean is a true or false value.
+	TypeBoolean Type = "boolean"
+	// TypeSmallInt is a 16 bits integer.
+	TypeSmallInt Type = "smallint"
+	// TypeInteger is a 32 bits integer.
+	TypeInteger Type = "integer"
+	// TypeBigInt is a 64 bits integer.
+	TypeBigInt Type = "bigint"
+	// TypeDecimal is an exact number with a precision and a scale.
+	TypeDecimal Type = "decimal"
+	// TypeFloat is a single precision floating point number.
+	TypeFloat Type = "float"
+	// TypeDouble is a double precision floating point number.
+	TypeDouble Type = "double"
+	// TypeString is a string with a maximum length.
+	TypeString Type = "string"
+	// TypeText is a string of unlimited length.
+	TypeText Type = "text"
+	// TypeBinary is a byte string.
+	TypeBinary Type = "binary"
+	// TypeDate is a date without time.
+	TypeDate Type = "date"
+	// TypeTime is a time of day.
+	TypeTime Type = "time"
+	// TypeTimestamp is a date and time.
+	TypeTimestamp Type = "timestamp"
+	// TypeJSON is a JSON document.
+	TypeJSON Type = "json"
+	// TypeUUID is a UUID.
+	TypeUUID Type = "uuid"
+	// TypeEnum is one of a list of strings.
+	TypeEnum Type = "enum"
+)
+
+type (
+	// Schema is the description of the tables of a database that does not depend on its SQL dialect.
+	Schema struct {
+		// Dialect is the dialect of the database the schema was read from.
+		Dialect string
+		// Tables are the tables of the database.
+		Tables []*TableSchema
+	}
+
+	// TableSchema is the description of a table.
+	TableSchema struct {
+		// Name is the table name.
+		Name string
+		// Columns are the columns of the table, in their order.
+		Columns []*Column
+		// PrimaryKey are the columns of the primary key, in their order.
+		PrimaryKey []string
+		// Indexes are the unique and secondary indexes of the table.
+		Indexes []*Index
+	}
+
+	// Index is an index of a table on plain columns.
+	Index struct {
+		// Name is the index name.
+		Name string
+		// Columns are the indexed columns, in their order.
+		Columns []string
+		// Unique is set when the index is a unique constraint.
+		Unique bool
+	}
+
+	// Column is the description of a table column.
+	Column struct {
+		// Name is the column name.
+		Name string
+		// Type is the dialect neutral type of the column.
+		Type Type
+		// Length is the maximum length of string columns, 0 when unbounded.
+		Length int64
+		// Precision is the number of digits of decimal columns.
+		Precision int64
+		// Scale is the number of digits after the decimal point of decimal columns.
+		Scale int64
+		// Nullable is set when the column accepts NULL values.
+		Nullable bool
+		// AutoIncrement is set when the column is generated by a sequence.
+		AutoIncrement bool
+		// Values are the accepted values of enum columns.
+		Values []string
+	}
+
+	// Type is a column type that does not depend on the SQL dialect.
+	Type string
+)
+
+// Table returns the schema of a table, nil when the table is unknown.
+func (s *Schema) Table(name string) *TableSchema {
+	if s == nil {
+		return nil
+	}
+
+	for _, table := range s.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+
+	return nil
+}
+
+// Column returns a column of the table, nil when the column is unknown.
+func (t *TableSchema) Column(name string) *Column {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+
+	return nil
+}
+
+// AddIndexColumn adds the next column of an index, the index is added with its first column.
+func (t *TableSchema) AddIndexColumn(name string, unique bool, column string) {
+	for _, index := range t.Indexes {
+		if index.Name == name {
+			index.Columns = append(index.Columns, column)
+			return
+		}
+	}
+
+	t.Indexes = append(t.Indexes, &Index{Name: name, Columns: []string{column}, Unique: unique})
+}
+
+// RemoveIndex removes an index of the table.
+func (t *TableSchema) RemoveIndex(name string) {
+	indexes := t.Indexes[:0]
+	for _, index := range t.Indexes {
+		if index.Name != name {
+			indexes = append(indexes, index)
+		}
+	}
+	t.Indexes = indexes
+}
+
+// IsIndexed checks if the column is part of an index of the table.
+func (t *TableSchema) IsIndexed(name string) bool {
+	for _, index := range t.Indexes {
+		for _, column := range index.Columns {
+			if column == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsPrimaryKey checks if the column is part of the primary key of the table.
+func (t *TableSchema) IsPrimaryKey(name string) bool {
+	for _, key := range t.PrimaryKey {
+		if key == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/incremental"
 	"github.com/hellofresh/klepto/pkg/reader"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -21,7 +22,7 @@ type (
 	// A Dumper writes a database's structure to the provided stream.
 	Dumper interface {
 		// Dump executes the dump process.
-		Dump(chan<- struct{}, *config.Spec, int) error
+		Dump(chan<- struct{}, *config.Spec, DumpOpts) error
 		// DumpViews executes the view dumping process
 		DumpViews(chan<- struct{}, *config.Spec, string, string) error
 		// GetDatabaseName returns the name of currently active SQL database
@@ -30,6 +31,20 @@ type (
 		Close() error
 	}
 
+	// DumpOpts are the options of a dump
+	DumpOpts struct {
+		// Concurrency is the number of tables dumped concurrently.
+		Concurrency int
+		// Incremental only copies the rows of the incremental tables changed since the last dump, into the existing tables.
+		Incremental bool
+		// State holds the watermarks of the incremental tables, they are not tracked when nil.
+		State *incremental.State
+		// SchemaOnly only dumps the structure.
+		SchemaOnly bool
+		// DataOnly only dumps the data, into the existing tables of the target.
+		DataOnly bool
+	}
+
 	// ConnOpts are the options to create a connection
 	ConnOpts struct {
 		// DSN is the connection address.
@@ -45,6 +60,19 @@ type (
 	}
 )
 
+// Validate checks that the dump options can be combined.
+func (o DumpOpts) Validate() error {
+	if o.SchemaOnly && o.DataOnly {
+		return errors.New("schema only and data only dumps cannot be combined")
+	}
+
+	if o.SchemaOnly && o.Incremental {
+		return errors.New("incremental dumps copy data, they cannot be schema only")
+	}
+
+	return nil
+}
+
 // NewDumper is a factory method that will create a dumper based on the provided DSN
 func NewDumper(opts ConnOpts, rdr reader.Reader) (dumper Dumper, err error) {
 	drivers.Range(func(key, value interface{}) bool {
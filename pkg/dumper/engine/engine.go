@@ -1,14 +1,16 @@
 package engine
 
 import (
-	"sync"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/hellofresh/klepto/pkg/config"
 	"github.com/hellofresh/klepto/pkg/database"
 	"github.com/hellofresh/klepto/pkg/dumper"
+	"github.com/hellofresh/klepto/pkg/incremental"
 	"github.com/hellofresh/klepto/pkg/reader"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -28,13 +30,49 @@ type (
 		// DumpViewDefinitions dumps database view definitions given as sql
 		DumpViewDefinitions(sql string) error
 		// DumpTable dumps a table by name.
-		DumpTable(tableName string, rowChan <-chan database.Row) error
+		DumpTable(tableName string, rowChan <-chan database.Row, opts TableOpts) error
 		// GetDatabaseName returns the name of currently active SQL database
 		GetDatabaseName() (string, error)
 		// Close closes the dumper resources and releases them.
 		Close() error
 	}
 
+	// TableOpts are the options of a table dump.
+	TableOpts struct {
+		// WriteMode is how the rows that already exist are handled.
+		WriteMode config.WriteMode
+		// Columns are the target names of the columns written.
+		Columns []string
+		// SourceSchema is the schema of a table read from another database engine, its values are converted when set.
+		SourceSchema *database.TableSchema
+	}
+
+	// SchemaDumper is implemented by dumpers that can create the tables of a database of another engine.
+	SchemaDumper interface {
+		// Dialect returns the SQL dialect of the target database.
+		Dialect() string
+		// DumpSchema creates the tables described by the schema.
+		DumpSchema(*database.Schema) error
+	}
+
+	// Transformer is implemented by dumpers that can change the structure of the target tables.
+	Transformer interface {
+		// TransformTable returns the SQL changing the structure of a table as configured, the table exists on the target.
+		TransformTable(tableName string, transform config.Transform) (string, error)
+	}
+
+	// ColumnLister is implemented by dumpers that can read the columns of the target tables.
+	ColumnLister interface {
+		// GetColumns returns the columns of a target table, none when the table does not exist.
+		GetColumns(tableName string) ([]string, error)
+	}
+
+	// watermarkTracker follows the highest value of the incremental column of the rows.
+	watermarkTracker struct {
+		column string
+		max    interface{}
+	}
+
 	// Hooker are the actions you perform before or after a specified database operation.
 	Hooker interface {
 		// PreDumpTables performs a action before dumping tables before dumping tables.
@@ -53,25 +91,262 @@ func New(rdr reader.Reader, dumper Dumper) dumper.Dumper {
 }
 
 // Dump executes the dump process.
-func (e *Engine) Dump(done chan<- struct{}, spec *config.Spec, concurrency int) error {
-	if err := e.readAndDumpStructure(); err != nil {
+func (e *Engine) Dump(done chan<- struct{}, spec *config.Spec, opts dumper.DumpOpts) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	for _, table := range spec.Tables {
+		if err := table.WriteMode.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid write mode of %s", table.Name)
+		}
+	}
+
+	if err := spec.Hooks.Validate(); err != nil {
+		return err
+	}
+
+	schema, err := e.sourceSchema()
+	if err != nil {
 		return err
 	}
 
-	return e.readAndDumpTables(done, spec, concurrency)
+	// Incremental and data only dumps write into the existing tables
+	var postData *database.Structure
+	if !opts.Incremental && !opts.DataOnly {
+		if err := e.runHook("BeforeStructure", spec.Hooks.BeforeStructure); err != nil {
+			return err
+		}
+
+		// Without data there is nothing to load faster
+		if postData, err = e.readAndDumpStructure(schema, spec.Tables, !opts.SchemaOnly); err != nil {
+			return err
+		}
+	}
+
+	if opts.SchemaOnly {
+		go func() {
+			done <- struct{}{}
+		}()
+
+		return nil
+	}
+
+	if err := e.runHook("BeforeData", spec.Hooks.BeforeData); err != nil {
+		return err
+	}
+
+	return e.readAndDumpTables(done, spec, opts, schema, postData)
+}
+
+// runHook executes the script of a hook against the target.
+func (e *Engine) runHook(name string, hook config.Hook) error {
+	script, err := hook.Script()
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the %s hook", name)
+	}
+
+	if strings.TrimSpace(script) == "" {
+		return nil
+	}
+
+	log.WithField("hook", name).Debug("running hook")
+	if err := e.DumpStructure(script); err != nil {
+		return errors.Wrapf(err, "failed to run the %s hook", name)
+	}
+
+	return nil
+}
+
+// sourceSchema returns the schema of the source database when it has another dialect than the target, nil otherwise.
+func (e *Engine) sourceSchema() (*database.Schema, error) {
+	schemaDumper, ok := e.Dumper.(SchemaDumper)
+	if !ok || schemaDumper.Dialect() == e.reader.Dialect() {
+		return nil, nil
+	}
+
+	log.WithFields(log.Fields{
+		"from": e.reader.Dialect(),
+		"to":   schemaDumper.Dialect(),
+	}).Info("copying across database engines")
+
+	schema, err := e.reader.GetSchema()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get schema")
+	}
+
+	return schema, nil
 }
 
 // DumpViews dumps views from one database to another.
 func (e *Engine) DumpViews(done chan<- struct{}, spec *config.Spec, sourceDbPrefix string, destinationDbPrefix string) error {
 	err := e.readAndDumpViews(spec, sourceDbPrefix, destinationDbPrefix)
-	
+
 	go func() {
 		done <- struct{}{}
 	}()
-	
+
 	return err
 }
 
+// applyWatermark restricts the read options to the rows changed since the watermark of the table.
+func (e *Engine) applyWatermark(tableName string, column string, opts *reader.ReadTableOpt, state *incremental.State) error {
+	if state == nil {
+		return errors.New("incremental dumps require a state")
+	}
+
+	watermark, ok, err := state.Get(tableName, column)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		log.WithField("table", tableName).Warn("no watermark found, all rows are copied")
+		return nil
+	}
+
+	opts.IncrementalColumn = column
+	opts.Watermark = watermark
+
+	return nil
+}
+
+// mapColumns returns the source columns that also exist on the target, logging the columns missing on either side.
+func mapColumns(tableName string, source []string, target []string) []string {
+	onTarget := make(map[string]bool, len(target))
+	for _, column := range target {
+		onTarget[column] = true
+	}
+
+	onSource := make(map[string]bool, len(source))
+	columns := make([]string, 0, len(source))
+	for _, column := range source {
+		onSource[column] = true
+		if !onTarget[column] {
+			log.WithField("table", tableName).WithField("column", column).Warn("column is missing on the target, it is not copied")
+			continue
+		}
+		columns = append(columns, column)
+	}
+
+	for _, column := range target {
+		if !onSource[column] {
+			log.WithField("table", tableName).WithField("column", column).Warn("column is missing on the source, it gets its default value")
+		}
+	}
+
+	return columns
+}
+
+// tableColumns returns the target names of the source columns written into the table.
+// The columns are mapped against the target table when it already exists.
+func (e *Engine) tableColumns(tableName string, transform config.Transform, existing bool) ([]string, error) {
+	source, err := e.reader.GetColumns(tableName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the columns of %s", tableName)
+	}
+
+	columns := make([]string, len(source))
+	for i, column := range source {
+		columns[i] = transform.ColumnName(column)
+	}
+
+	lister, canList := e.Dumper.(ColumnLister)
+	if !existing || !canList {
+		return columns, nil
+	}
+
+	targetName := transform.TableName(tableName)
+	target, err := lister.GetColumns(targetName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the target columns of %s", targetName)
+	}
+
+	// The table does not exist on the target
+	if len(target) == 0 {
+		return []string{}, nil
+	}
+
+	return mapColumns(targetName, columns, target), nil
+}
+
+// renameColumns renames the columns of the rows as they are named on the target.
+func renameColumns(transform config.Transform, rowChan <-chan database.Row) <-chan database.Row {
+	renamed := make(chan database.Row)
+	go func() {
+		defer close(renamed)
+
+		for row := range rowChan {
+			targetRow := make(database.Row, len(row))
+			for column, value := range row {
+				targetRow[transform.ColumnName(column)] = value
+			}
+
+			renamed <- targetRow
+		}
+	}()
+
+	return renamed
+}
+
+// transformSchema returns the schema of the table as it is named on the target.
+func transformSchema(table *database.TableSchema, transform config.Transform) *database.TableSchema {
+	if table == nil || transform.IsZero() {
+		return table
+	}
+
+	transformed := &database.TableSchema{Name: transform.TableName(table.Name)}
+	for _, column := range table.Columns {
+		renamed := *column
+		renamed.Name = transform.ColumnName(column.Name)
+		transformed.Columns = append(transformed.Columns, &renamed)
+	}
+
+	for _, key := range table.PrimaryKey {
+		transformed.PrimaryKey = append(transformed.PrimaryKey, transform.ColumnName(key))
+	}
+
+	return transformed
+}
+
+// ConvertRows converts the values of the rows read from another database engine with the convert function.
+func ConvertRows(table *database.TableSchema, rowChan <-chan database.Row, convert func(*database.Column, interface{}) interface{}) <-chan database.Row {
+	columns := make(map[string]*database.Column, len(table.Columns))
+	for _, column := range table.Columns {
+		columns[column.Name] = column
+	}
+
+	converted := make(chan database.Row)
+	go func() {
+		defer close(converted)
+
+		for row := range rowChan {
+			for name, value := range row {
+				if column, ok := columns[name]; ok {
+					row[name] = convert(column, value)
+				}
+			}
+
+			converted <- row
+		}
+	}()
+
+	return converted
+}
+
+// writeMode returns the write mode of the table, incremental dumps update the rows of a previous dump by default.
+func writeMode(tableConfig *config.Table, incremental bool) config.WriteMode {
+	if tableConfig != nil && tableConfig.WriteMode != "" {
+		return tableConfig.WriteMode
+	}
+
+	if incremental {
+		return config.WriteUpsert
+	}
+
+	return config.WriteAppend
+}
+
 func replacePrefix(sourcePrefix string, destinationPrefix string) func(string) string {
 	return func(input string) string {
 		return strings.Replace(input, sourcePrefix, destinationPrefix, 1)
@@ -80,7 +355,7 @@ func replacePrefix(sourcePrefix string, destinationPrefix string) func(string) s
 
 func (e *Engine) readAndDumpViews(spec *config.Spec, sourceDbPrefix string, destinationDbPrefix string) error {
 	log.Debug("dumping views...")
-	
+
 	sql, err := e.reader.GetViewDefinitions(spec)
 
 	if err != nil {
@@ -103,35 +378,233 @@ func (e *Engine) readAndDumpViews(spec *config.Spec, sourceDbPrefix string, dest
 	return nil
 }
 
-func (e *Engine) readAndDumpStructure() error {
+// readAndDumpStructure creates the tables, the post-data of the tables is returned when it is created after their data.
+func (e *Engine) readAndDumpStructure(schema *database.Schema, tables config.Tables, deferPostData bool) (*database.Structure, error) {
 	log.Debug("dumping structure...")
+
+	// The structure SQL of another engine cannot be executed on the target
+	if schema != nil {
+		if err := e.Dumper.(SchemaDumper).DumpSchema(schema); err != nil {
+			return nil, errors.Wrap(err, "failed to dump schema")
+		}
+
+		if err := e.transformTables(tables); err != nil {
+			return nil, err
+		}
+
+		log.Debug("structure was dumped")
+		return nil, nil
+	}
+
+	if deferPostData {
+		return e.dumpPreData(tables)
+	}
+
 	sql, err := e.reader.GetStructure()
 	if err != nil {
-		return errors.Wrap(err, "failed to get structure")
+		return nil, errors.Wrap(err, "failed to get structure")
 	}
 
 	if err := e.DumpStructure(sql); err != nil {
-		return errors.Wrap(err, "failed to dump structure")
+		return nil, errors.Wrap(err, "failed to dump structure")
+	}
+
+	if err := e.transformTables(tables); err != nil {
+		return nil, err
 	}
 
 	log.Debug("structure was dumped")
+	return nil, nil
+}
+
+// dumpPreData creates the tables, the post-data of the tables written in append mode is returned to be created after their data.
+// The other write modes need the keys of the tables. The transforms change the tables, indexes and columns the post-data
+// refers to, so the post-data of the transformed tables and the foreign keys referring to them are created before the transforms.
+func (e *Engine) dumpPreData(tables config.Tables) (*database.Structure, error) {
+	structure, err := e.reader.GetStructureSections()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get structure")
+	}
+
+	early := make(map[string]bool)
+	for _, tableName := range sortedTables(structure.PostData) {
+		tableConfig, _ := tables.FindByName(tableName)
+		if writeMode(tableConfig, false) != config.WriteAppend || (tableConfig != nil && !tableConfig.Transform.IsZero()) {
+			early[tableName] = true
+		}
+	}
+
+	// The foreign keys need the keys of the tables they refer to
+	var earlyForeignKeys, deferredForeignKeys []string
+	for _, statement := range structure.ForeignKeys {
+		if !refersToTransformed(statement, tables) {
+			deferredForeignKeys = append(deferredForeignKeys, statement)
+			continue
+		}
+
+		earlyForeignKeys = append(earlyForeignKeys, statement)
+		for tableName := range structure.PostData {
+			if refersTo(statement, tableName) {
+				early[tableName] = true
+			}
+		}
+	}
+	structure.ForeignKeys = deferredForeignKeys
+
+	statements := []string{structure.PreData}
+	for _, tableName := range sortedTables(structure.PostData) {
+		if early[tableName] {
+			statements = append(statements, structure.PostData[tableName]...)
+			delete(structure.PostData, tableName)
+		}
+	}
+	statements = append(statements, earlyForeignKeys...)
+
+	if err := e.DumpStructure(strings.Join(statements, "\n")); err != nil {
+		return nil, errors.Wrap(err, "failed to dump structure")
+	}
+
+	if err := e.transformTables(tables); err != nil {
+		return nil, err
+	}
+
+	log.Debug("pre-data was dumped")
+	return structure, nil
+}
+
+// refersToTransformed checks if a statement refers to a transformed table.
+func refersToTransformed(statement string, tables config.Tables) bool {
+	for _, table := range tables {
+		if !table.Transform.IsZero() && refersTo(statement, table.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refersTo checks if a statement contains the name of the table, quoted or not.
+// A column of the same name also matches, which only creates more of the structure before the data.
+func refersTo(statement string, tableName string) bool {
+	return regexp.MustCompile(`(^|[^\w$])` + regexp.QuoteMeta(tableName) + `([^\w$]|$)`).MatchString(statement)
+}
+
+// dumpPostData creates the indexes, constraints and triggers of the tables concurrently, then the foreign keys.
+func (e *Engine) dumpPostData(structure *database.Structure, concurrency int) error {
+	log.Debug("dumping post-data...")
+	tables := sortedTables(structure.PostData)
+	failedChan := make(chan string, len(tables))
+	semChan := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, tableName := range tables {
+		semChan <- struct{}{}
+		wg.Add(1)
+
+		go func(tableName string, statements []string) {
+			defer wg.Done()
+			defer func() { <-semChan }()
+
+			if err := e.DumpStructure(strings.Join(statements, "\n")); err != nil {
+				log.WithError(err).WithField("table", tableName).Error("failed to dump post-data")
+				failedChan <- tableName
+			}
+		}(tableName, structure.PostData[tableName])
+	}
+	wg.Wait()
+	close(failedChan)
+
+	if failed := collect(failedChan); len(failed) > 0 {
+		return errors.Errorf("failed to dump the post-data of %s", strings.Join(failed, ", "))
+	}
+
+	// Foreign keys need the keys of the referenced tables
+	if len(structure.ForeignKeys) > 0 {
+		if err := e.DumpStructure(strings.Join(structure.ForeignKeys, "\n")); err != nil {
+			return errors.Wrap(err, "failed to dump foreign keys")
+		}
+	}
+
+	log.Debug("post-data was dumped")
+	return nil
+}
+
+// collect returns the sorted values of a closed channel.
+func collect(c <-chan string) []string {
+	var values []string
+	for value := range c {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	return values
+}
+
+// sortedTables returns the tables of the post-data in alphabetical order.
+func sortedTables(postData map[string][]string) []string {
+	tables := make([]string, 0, len(postData))
+	for table := range postData {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	return tables
+}
+
+// transformTables changes the structure of the transformed tables once they are created on the target.
+func (e *Engine) transformTables(tables config.Tables) error {
+	var statements []string
+	for _, table := range tables {
+		if table.Transform.IsZero() {
+			continue
+		}
+
+		transformer, ok := e.Dumper.(Transformer)
+		if !ok {
+			return errors.New("dumper cannot transform tables")
+		}
+
+		sql, err := transformer.TransformTable(table.Name, table.Transform)
+		if err != nil {
+			return errors.Wrapf(err, "failed to transform %s", table.Name)
+		}
+		statements = append(statements, sql)
+	}
+
+	if len(statements) == 0 {
+		return nil
+	}
+
+	if err := e.DumpStructure(strings.Join(statements, "\n")); err != nil {
+		return errors.Wrap(err, "failed to transform structure")
+	}
+
 	return nil
 }
 
-func (e *Engine) readAndDumpTables(done chan<- struct{}, spec *config.Spec, concurrency int) error {
+func (e *Engine) readAndDumpTables(done chan<- struct{}, spec *config.Spec, dumpOpts dumper.DumpOpts, schema *database.Schema, postData *database.Structure) error {
 	tables, err := e.reader.GetTables()
 	if err != nil {
 		return errors.Wrap(err, "failed to read and dump tables")
 	}
 
+	// The hooks run on the tables as they are named on the target
+	targetTables := make([]string, len(tables))
+	for i, tbl := range tables {
+		targetTables[i] = tbl
+		if tableConfig, err := spec.Tables.FindByName(tbl); err == nil {
+			targetTables[i] = tableConfig.Transform.TableName(tbl)
+		}
+	}
+
 	// Trigger pre dump tables
 	if adv, ok := e.Dumper.(Hooker); ok {
-		if err := adv.PreDumpTables(tables); err != nil {
+		if err := adv.PreDumpTables(targetTables); err != nil {
 			return errors.Wrap(err, "failed to execute pre dump tables")
 		}
 	}
 
-	semChan := make(chan struct{}, concurrency)
+	hookFailedChan := make(chan string, len(tables))
+	semChan := make(chan struct{}, dumpOpts.Concurrency)
 	var wg sync.WaitGroup
 	for _, tbl := range tables {
 		logger := log.WithField("table", tbl)
@@ -140,71 +613,135 @@ func (e *Engine) readAndDumpTables(done chan<- struct{}, spec *config.Spec, conc
 			logger.WithError(err).Debug("no configuration found for table")
 		}
 
-		var opts reader.ReadTableOpt
+		if tableConfig != nil && tableConfig.IgnoreData {
+			logger.Debug("ignoring data to dump")
+			continue
+		}
+
+		opts := reader.NewReadTableOpt(tableConfig, spec)
+
+		var incrementalColumn string
 		if tableConfig != nil {
-			if tableConfig.IgnoreData {
-				logger.Debug("ignoring data to dump")
+			incrementalColumn = tableConfig.Incremental.Column
+		}
+
+		if dumpOpts.Incremental {
+			if incrementalColumn == "" {
+				logger.Debug("ignoring data of a table that is not incremental")
 				continue
 			}
 
-			opts = reader.ReadTableOpt{
-				Match:         tableConfig.Filter.Match,
-				Sorts:         tableConfig.Filter.Sorts,
-				Limit:         tableConfig.Filter.Limit,
-				Relationships: e.relationshipConfigToOptions(tableConfig.Relationships),
+			if err := e.applyWatermark(tbl, incrementalColumn, &opts, dumpOpts.State); err != nil {
+				return err
 			}
 		}
 
+		var transform config.Transform
+		if tableConfig != nil {
+			transform = tableConfig.Transform
+		}
+
+		tableOpts := TableOpts{
+			WriteMode:    writeMode(tableConfig, dumpOpts.Incremental),
+			SourceSchema: transformSchema(schema.Table(tbl), transform),
+		}
+
+		// Incremental and data only dumps write into the existing tables
+		tableOpts.Columns, err = e.tableColumns(tbl, transform, dumpOpts.Incremental || dumpOpts.DataOnly)
+		if err != nil {
+			return err
+		}
+
+		if len(tableOpts.Columns) == 0 {
+			logger.Warn("no columns of the table exist on the target, ignoring data")
+			continue
+		}
+
+		// Only the watermarks of the incremental tables are tracked, from the source
+		// values so that the anonymisation of the column doesn't corrupt them
+		var tracker *watermarkTracker
+		if incrementalColumn != "" && dumpOpts.State != nil {
+			tracker = &watermarkTracker{column: incrementalColumn}
+			opts.OnRow = tracker.track
+		}
+
 		// Create read/write chanel
 		rowChan := make(chan database.Row)
+		readErrChan := make(chan error, 1)
 		semChan <- struct{}{}
 		wg.Add(1)
 
-		go func(tableName string, rowChan <-chan database.Row, logger *log.Entry) {
+		go func(tableName string, transform config.Transform, rowChan <-chan database.Row, tableOpts TableOpts, tracker *watermarkTracker, logger *log.Entry) {
 			defer wg.Done()
 			defer func(semChan <-chan struct{}) { <-semChan }(semChan)
 
-			if err := e.DumpTable(tableName, rowChan); err != nil {
+			if len(transform.Columns) > 0 {
+				rowChan = renameColumns(transform, rowChan)
+			}
+
+			if err := e.DumpTable(transform.TableName(tableName), rowChan, tableOpts); err != nil {
 				logger.WithError(err).Error("Failed to dump table")
+				return
 			}
-		}(tbl, rowChan, logger)
+
+			// The watermark moves only when the whole table was copied
+			if readErr := <-readErrChan; readErr == nil && tracker != nil && tracker.max != nil {
+				dumpOpts.State.Set(tableName, tracker.column, tracker.max)
+			}
+
+			if err := e.runHook("AfterTable", spec.Hooks.TableHook(tableName)); err != nil {
+				logger.WithError(err).Error("Failed to run table hook")
+				hookFailedChan <- tableName
+			}
+		}(tbl, transform, rowChan, tableOpts, tracker, logger)
 
 		go func(tableName string, opts reader.ReadTableOpt, rowChan chan<- database.Row, logger *log.Entry) {
-			if err := e.reader.ReadTable(tableName, rowChan, opts, spec.Matchers); err != nil {
+			err := e.reader.ReadTable(tableName, rowChan, opts, spec.Matchers)
+			if err != nil {
 				logger.WithError(err).Error("Failed to read table")
 			}
+			readErrChan <- err
 		}(tbl, opts, rowChan, logger)
 	}
 
-	go func() {
-		// Wait for all table to be dumped
-		wg.Wait()
-		close(semChan)
-
-		// Trigger post dump tables
-		if adv, ok := e.Dumper.(Hooker); ok {
-			if err := adv.PostDumpTables(tables); err != nil {
-				log.WithError(err).Error("post dump tables failed")
-			}
-		}
+	// Wait for all table to be dumped
+	wg.Wait()
+	close(semChan)
+	close(hookFailedChan)
 
+	go func() {
 		done <- struct{}{}
 	}()
 
-	return nil
-}
+	if dumpOpts.State != nil {
+		if err := dumpOpts.State.Save(); err != nil {
+			log.WithError(err).Error("failed to save the incremental state")
+		}
+	}
 
-func (e *Engine) relationshipConfigToOptions(relationshipsConfig []*config.Relationship) []*reader.RelationshipOpt {
-	var opts []*reader.RelationshipOpt
+	if postData != nil {
+		err = e.dumpPostData(postData, dumpOpts.Concurrency)
+	}
 
-	for _, r := range relationshipsConfig {
-		opts = append(opts, &reader.RelationshipOpt{
-			Table:           r.Table,
-			ReferencedTable: r.ReferencedTable,
-			ReferencedKey:   r.ReferencedKey,
-			ForeignKey:      r.ForeignKey,
-		})
+	if failed := collect(hookFailedChan); len(failed) > 0 && err == nil {
+		err = errors.Errorf("failed to run the AfterTable hook of %s", strings.Join(failed, ", "))
 	}
 
-	return opts
+	// Trigger post dump tables, also after a failure to leave the target as usable as possible
+	if adv, ok := e.Dumper.(Hooker); ok {
+		if err := adv.PostDumpTables(targetTables); err != nil {
+			log.WithError(err).Error("post dump tables failed")
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return e.runHook("AfterData", spec.Hooks.AfterData)
+}
+
+// track records the highest value of the column.
+func (t *watermarkTracker) track(row database.Row) {
+	t.max = incremental.Max(t.max, row[t.column])
 }
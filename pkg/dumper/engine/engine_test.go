@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		source   []string
+		target   []string
+		expected []string
+	}{
+		{
+			scenario: "when the columns are the same",
+			source:   []string{"id", "email"},
+			target:   []string{"email", "id"},
+			expected: []string{"id", "email"},
+		},
+		{
+			scenario: "when a column is missing on the target",
+			source:   []string{"id", "legacy_code", "email"},
+			target:   []string{"id", "email"},
+			expected: []string{"id", "email"},
+		},
+		{
+			scenario: "when a column is missing on the source",
+			source:   []string{"id"},
+			target:   []string{"id", "created_at"},
+			expected: []string{"id"},
+		},
+		{
+			scenario: "when no column exists on the target",
+			source:   []string{"id"},
+			target:   []string{"uuid"},
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			assert.Equal(t, test.expected, mapColumns("users", test.source, test.target))
+		})
+	}
+}
+
+func TestTransformSchema(t *testing.T) {
+	t.Parallel()
+
+	table := &database.TableSchema{
+		Name:       "tbl_user",
+		Columns:    []*database.Column{{Name: "usr_id", Type: database.TypeBigInt}, {Name: "email", Type: database.TypeString}},
+		PrimaryKey: []string{"usr_id"},
+	}
+	transform := config.Transform{Rename: "users", Columns: map[string]string{"usr_id": "id"}}
+
+	expected := &database.TableSchema{
+		Name:       "users",
+		Columns:    []*database.Column{{Name: "id", Type: database.TypeBigInt}, {Name: "email", Type: database.TypeString}},
+		PrimaryKey: []string{"id"},
+	}
+
+	assert.Equal(t, expected, transformSchema(table, transform))
+	assert.Equal(t, "usr_id", table.Columns[0].Name, "the source schema is not changed")
+	assert.Nil(t, transformSchema(nil, transform))
+}
+
+func TestRenameColumns(t *testing.T) {
+	t.Parallel()
+
+	rowChan := make(chan database.Row, 1)
+	rowChan <- database.Row{"usr_name": "John", "id": 1}
+	close(rowChan)
+
+	var rows []database.Row
+	for row := range renameColumns(config.Transform{Columns: map[string]string{"usr_name": "name"}}, rowChan) {
+		rows = append(rows, row)
+	}
+
+	assert.Equal(t, []database.Row{{"name": "John", "id": 1}}, rows)
+}
+
+func TestDumpPostData(t *testing.T) {
+	t.Parallel()
+
+	structure := func() *database.Structure {
+		return &database.Structure{
+			PostData:    map[string][]string{"orders": {"CREATE INDEX orders_user_id ON orders (user_id);"}, "users": {"CREATE INDEX users_email ON users (email);"}},
+			ForeignKeys: []string{"ALTER TABLE orders ADD FOREIGN KEY (user_id) REFERENCES users (id);"},
+		}
+	}
+
+	tests := []struct {
+		scenario string
+		failing  string
+		err      string
+		dumped   int
+	}{
+		{
+			scenario: "when the post-data is dumped",
+			dumped:   3,
+		},
+		{
+			scenario: "when the post-data of a table fails",
+			failing:  "users_email",
+			err:      "failed to dump the post-data of users",
+			dumped:   1,
+		},
+		{
+			scenario: "when the foreign keys fail",
+			failing:  "FOREIGN KEY",
+			err:      "failed to dump foreign keys: syntax error",
+			dumped:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			dumper := &mockDumper{failing: test.failing}
+			e := &Engine{Dumper: dumper}
+
+			err := e.dumpPostData(structure(), 1)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, dumper.dumped, test.dumped)
+		})
+	}
+}
+
+func TestDumpPreData(t *testing.T) {
+	t.Parallel()
+
+	structure := &database.Structure{
+		PreData: "CREATE TABLE tbl_user (id int);\nCREATE TABLE orders (id int, user_id int);\nCREATE TABLE products (id int);",
+		PostData: map[string][]string{
+			"tbl_user": {"ALTER TABLE tbl_user ADD PRIMARY KEY (id);"},
+			"orders":   {"ALTER TABLE orders ADD PRIMARY KEY (id);"},
+			"products": {"ALTER TABLE products ADD PRIMARY KEY (id);"},
+		},
+		ForeignKeys: []string{
+			"ALTER TABLE orders ADD FOREIGN KEY (user_id) REFERENCES tbl_user (id);",
+			"ALTER TABLE products ADD FOREIGN KEY (id) REFERENCES products_archive (id);",
+		},
+	}
+	tables := config.Tables{
+		{Name: "tbl_user", Transform: config.Transform{Rename: "users"}},
+		{Name: "products"},
+	}
+
+	dumper := &mockDumper{}
+	e := &Engine{Dumper: dumper, reader: &mockReader{structure: structure}}
+
+	postData, err := e.dumpPreData(tables)
+	require.NoError(t, err)
+
+	// The transformed table and the tables of its foreign keys are created before the transform
+	assert.Equal(t, []string{
+		"CREATE TABLE tbl_user (id int);\nCREATE TABLE orders (id int, user_id int);\nCREATE TABLE products (id int);\n" +
+			"ALTER TABLE orders ADD PRIMARY KEY (id);\n" +
+			"ALTER TABLE tbl_user ADD PRIMARY KEY (id);\n" +
+			"ALTER TABLE orders ADD FOREIGN KEY (user_id) REFERENCES tbl_user (id);",
+		"ALTER TABLE tbl_user RENAME TO users;",
+	}, dumper.dumped)
+
+	// The untransformed table is still created after its data
+	assert.Equal(t, map[string][]string{"products": {"ALTER TABLE products ADD PRIMARY KEY (id);"}}, postData.PostData)
+	assert.Equal(t, []string{"ALTER TABLE products ADD FOREIGN KEY (id) REFERENCES products_archive (id);"}, postData.ForeignKeys)
+}
+
+func TestTableColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario  string
+		transform config.Transform
+		dumper    Dumper
+		existing  bool
+		expected  []string
+	}{
+		{
+			scenario: "when the table is not transformed",
+			expected: []string{"usr_id", "email"},
+		},
+		{
+			scenario:  "when the table is only renamed",
+			transform: config.Transform{Rename: "users"},
+			expected:  []string{"usr_id", "email"},
+		},
+		{
+			scenario:  "when the columns are renamed",
+			transform: config.Transform{Rename: "users", Columns: map[string]string{"usr_id": "id"}},
+			expected:  []string{"id", "email"},
+		},
+		{
+			scenario:  "when the renamed table exists on the target",
+			transform: config.Transform{Rename: "users", Columns: map[string]string{"usr_id": "id"}},
+			dumper:    &mockDumper{columns: map[string][]string{"users": {"id", "created_at"}}},
+			existing:  true,
+			expected:  []string{"id"},
+		},
+		{
+			scenario:  "when the table does not exist on the target",
+			transform: config.Transform{Rename: "users"},
+			dumper:    &mockDumper{},
+			existing:  true,
+			expected:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			e := &Engine{Dumper: test.dumper, reader: &mockReader{columns: []string{"usr_id", "email"}}}
+
+			columns, err := e.tableColumns("tbl_user", test.transform, test.existing)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, columns)
+		})
+	}
+}
+
+type mockReader struct {
+	reader.Reader
+	columns   []string
+	structure *database.Structure
+}
+
+func (m *mockReader) GetColumns(string) ([]string, error) { return m.columns, nil }
+
+func (m *mockReader) GetStructureSections() (*database.Structure, error) { return m.structure, nil }
+
+type mockDumper struct {
+	Dumper
+	columns map[string][]string
+	failing string
+	dumped  []string
+}
+
+func (m *mockDumper) GetColumns(tableName string) ([]string, error) { return m.columns[tableName], nil }
+
+func (m *mockDumper) TransformTable(tableName string, transform config.Transform) (string, error) {
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", tableName, transform.Rename), nil
+}
+
+func (m *mockDumper) DumpStructure(sql string) error {
+	if m.failing != "" && strings.Contains(sql, m.failing) {
+		return errors.New("syntax error")
+	}
+	m.dumped = append(m.dumped, sql)
+	return nil
+}
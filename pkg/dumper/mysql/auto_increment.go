@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// PreDumpTables does nothing, foreign key checks are disabled by the transactions loading the rows.
+func (d *myDumper) PreDumpTables(tables []string) error {
+	return nil
+}
+
+// PostDumpTables resets the AUTO_INCREMENT counters of the tables.
+func (d *myDumper) PostDumpTables(tables []string) error {
+	columns, err := d.autoIncrementColumns(tables)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		column, ok := columns[table]
+		if !ok {
+			continue
+		}
+
+		if err := d.resetAutoIncrement(table, column); err != nil {
+			return errors.Wrapf(err, "failed to reset the auto increment of %s", table)
+		}
+	}
+
+	return nil
+}
+
+// autoIncrementColumns returns the auto incremented column of the tables, by table.
+func (d *myDumper) autoIncrementColumns(tables []string) (map[string]string, error) {
+	rows, err := d.conn.Query(
+		"SELECT `table_name`, `column_name` FROM `information_schema`.`columns` WHERE table_schema=DATABASE() AND `extra` LIKE '%auto_increment%'",
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get auto increment columns")
+	}
+	defer rows.Close()
+
+	wanted := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		wanted[table] = true
+	}
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+
+		if wanted[table] {
+			columns[table] = column
+		}
+	}
+
+	return columns, rows.Err()
+}
+
+// resetAutoIncrement sets the AUTO_INCREMENT counter of the table after the highest value of its column.
+// The structure copies the counter of the source, which is too high when only a subset of the rows was copied.
+func (d *myDumper) resetAutoIncrement(table string, column string) error {
+	var next int64
+	query := fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) + 1 FROM %s", d.quoteIdentifier(column), d.quoteIdentifier(table))
+	if err := d.conn.QueryRow(query).Scan(&next); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{"table": table, "next": next}).Debug("resetting auto increment")
+	_, err := d.conn.Exec(fmt.Sprintf("ALTER TABLE %s AUTO_INCREMENT = %d", d.quoteIdentifier(table), next))
+
+	return err
+}
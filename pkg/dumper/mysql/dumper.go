@@ -5,10 +5,13 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/hellofresh/klepto/pkg/config"
 	"github.com/hellofresh/klepto/pkg/database"
 	"github.com/hellofresh/klepto/pkg/dumper"
 	"github.com/hellofresh/klepto/pkg/dumper/engine"
@@ -18,21 +21,30 @@ import (
 )
 
 const (
-	null = "NULL"
+	null           = "NULL"
+	datetimeLayout = "2006-01-02 15:04:05.999999"
 )
 
 type (
 	myDumper struct {
-		conn   *sql.DB
-		reader reader.Reader
+		conn *sql.DB
+	}
+
+	// targetColumn is the definition of a column of a target table.
+	targetColumn struct {
+		name         string
+		columnType   string
+		nullable     bool
+		defaultValue sql.NullString
+		extra        string
+		collation    sql.NullString
 	}
 )
 
 // NewDumper returns a new mysql dumper.
 func NewDumper(conn *sql.DB, rdr reader.Reader) dumper.Dumper {
 	return engine.New(rdr, &myDumper{
-		conn:   conn,
-		reader: rdr,
+		conn: conn,
 	})
 }
 
@@ -50,6 +62,59 @@ func (d *myDumper) GetDatabaseName() (string, error) {
 	return dbName, nil
 }
 
+// GetColumns returns the columns of the target table.
+func (d *myDumper) GetColumns(tableName string) ([]string, error) {
+	rows, err := d.conn.Query(
+		"SELECT `column_name` FROM `information_schema`.`columns` WHERE table_schema=DATABASE() AND table_name=? ORDER BY `ordinal_position`",
+		tableName,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get target columns")
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
+// getTargetColumns returns the definitions of the columns of a target table, keyed by name.
+func (d *myDumper) getTargetColumns(tableName string) (map[string]*targetColumn, error) {
+	rows, err := d.conn.Query(
+		"SELECT `column_name`, `column_type`, `is_nullable`, `column_default`, `extra`, `collation_name` "+
+			"FROM `information_schema`.`columns` WHERE table_schema=DATABASE() AND table_name=?",
+		tableName,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get target columns")
+	}
+	defer rows.Close()
+
+	columns := make(map[string]*targetColumn)
+	for rows.Next() {
+		var (
+			column   targetColumn
+			nullable string
+		)
+		if err := rows.Scan(&column.name, &column.columnType, &nullable, &column.defaultValue, &column.extra, &column.collation); err != nil {
+			return nil, err
+		}
+
+		column.nullable = nullable == "YES"
+		columns[column.name] = &column
+	}
+
+	return columns, rows.Err()
+}
+
 // DumpStructure dump the mysql database structure.
 func (d *myDumper) DumpStructure(sql string) error {
 	if _, err := d.conn.Exec(sql); err != nil {
@@ -69,13 +134,13 @@ func (d *myDumper) DumpViewDefinitions(sql string) error {
 }
 
 // DumpTable dumps a mysql table.
-func (d *myDumper) DumpTable(tableName string, rowChan <-chan database.Row) error {
+func (d *myDumper) DumpTable(tableName string, rowChan <-chan database.Row, opts engine.TableOpts) error {
 	txn, err := d.conn.Begin()
 	if err != nil {
 		return errors.Wrap(err, "failed to open transaction")
 	}
 
-	insertedRows, err := d.insertIntoTable(txn, tableName, rowChan)
+	insertedRows, err := d.insertIntoTable(txn, tableName, rowChan, opts)
 	if err != nil {
 		defer func() {
 			if err := txn.Rollback(); err != nil {
@@ -107,20 +172,84 @@ func (d *myDumper) Close() error {
 	return nil
 }
 
-func (d *myDumper) insertIntoTable(txn *sql.Tx, tableName string, rowChan <-chan database.Row) (int64, error) {
-	columns, err := d.reader.GetColumns(tableName)
+func (d *myDumper) insertIntoTable(txn *sql.Tx, tableName string, rowChan <-chan database.Row, opts engine.TableOpts) (int64, error) {
+	columns := opts.Columns
+
+	if _, err := txn.Exec("SET foreign_key_checks = 0;"); err != nil {
+		return 0, errors.Wrap(err, "failed to disable foreign key checks")
+	}
+
+	if opts.SourceSchema != nil {
+		rowChan = engine.ConvertRows(opts.SourceSchema, rowChan, convertValue)
+	}
+
+	switch opts.WriteMode {
+	case config.WriteTruncate:
+		// TRUNCATE would commit the transaction
+		if _, err := txn.Exec(fmt.Sprintf("DELETE FROM %s", d.quoteIdentifier(tableName))); err != nil {
+			return 0, errors.Wrap(err, "failed to empty table")
+		}
+	case config.WriteSkipExisting:
+		return d.loadData(txn, tableName, tableName, columns, rowChan, "IGNORE")
+	case config.WriteUpsert:
+		return d.upsertIntoTable(txn, tableName, columns, rowChan)
+	}
+
+	return d.loadData(txn, tableName, tableName, columns, rowChan, "")
+}
+
+// upsertIntoTable loads the rows into a staging table, then inserts them into the table updating the existing ones.
+func (d *myDumper) upsertIntoTable(txn *sql.Tx, tableName string, columns []string, rowChan <-chan database.Row) (int64, error) {
+	staging := d.quoteIdentifier("klepto_upsert_" + tableName)
+	query := fmt.Sprintf("CREATE TEMPORARY TABLE %s LIKE %s", staging, d.quoteIdentifier(tableName))
+	if _, err := txn.Exec(query); err != nil {
+		return 0, errors.Wrap(err, "failed to create staging table")
+	}
+
+	defer func() {
+		if _, err := txn.Exec(fmt.Sprintf("DROP TEMPORARY TABLE %s", staging)); err != nil {
+			log.WithError(err).Error("failed to drop staging table")
+		}
+	}()
+
+	upserted, err := d.loadData(txn, tableName, "klepto_upsert_"+tableName, columns, rowChan, "")
 	if err != nil {
-		return 0, errors.Wrap(err, "failed to get columns")
+		return 0, err
 	}
 
+	columnsQuoted := make([]string, len(columns))
+	updates := make([]string, len(columns))
+	for i, column := range columns {
+		columnsQuoted[i] = d.quoteIdentifier(column)
+		updates[i] = fmt.Sprintf("%s = VALUES(%s)", columnsQuoted[i], columnsQuoted[i])
+	}
+
+	query = fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM %s ON DUPLICATE KEY UPDATE %s",
+		d.quoteIdentifier(tableName),
+		strings.Join(columnsQuoted, ","),
+		strings.Join(columnsQuoted, ","),
+		staging,
+		strings.Join(updates, ","),
+	)
+	if _, err := txn.Exec(query); err != nil {
+		return 0, errors.Wrap(err, "failed to upsert rows")
+	}
+
+	return upserted, nil
+}
+
+// loadData loads the rows of the table into the target table, duplicates is either empty, IGNORE or REPLACE.
+func (d *myDumper) loadData(txn *sql.Tx, tableName string, target string, columns []string, rowChan <-chan database.Row, duplicates string) (int64, error) {
 	columnsQuoted := make([]string, len(columns))
 	for i, column := range columns {
 		columnsQuoted[i] = d.quoteIdentifier(column)
 	}
 	query := fmt.Sprintf(
-		"LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 FIELDS TERMINATED BY ',' ENCLOSED BY '\"' ESCAPED BY '\"' (%s)",
+		"LOAD DATA LOCAL INFILE 'Reader::%s' %s INTO TABLE %s CHARACTER SET utf8mb4 FIELDS TERMINATED BY ',' ENCLOSED BY '\"' ESCAPED BY '\"' (%s)",
 		tableName,
-		d.quoteIdentifier(tableName),
+		duplicates,
+		d.quoteIdentifier(target),
 		strings.Join(columnsQuoted, ","),
 	)
 
@@ -149,6 +278,14 @@ func (d *myDumper) insertIntoTable(txn *sql.Tx, tableName string, rowChan <-chan
 					rowValues[i] = row[col].(string)
 				case []uint8:
 					rowValues[i] = string(row[col].([]uint8))
+				case int64:
+					rowValues[i] = strconv.FormatInt(v, 10)
+				case float64:
+					rowValues[i] = strconv.FormatFloat(v, 'f', -1, 64)
+				case bool:
+					rowValues[i] = boolValue(v)
+				case time.Time:
+					rowValues[i] = v.Format(datetimeLayout)
 				default:
 					log.WithField("type", v).Info("we have an unhandled type. attempting to convert to a string \n")
 					rowValues[i] = row[col].(string)
@@ -167,10 +304,6 @@ func (d *myDumper) insertIntoTable(txn *sql.Tx, tableName string, rowChan <-chan
 	mysql.RegisterReaderHandler(tableName, func() io.Reader { return rowReader })
 	defer mysql.DeregisterReaderHandler(tableName)
 
-	if _, err := txn.Exec("SET foreign_key_checks = 0;"); err != nil {
-		return 0, errors.Wrap(err, "failed to disable foreign key checks")
-	}
-
 	if _, err := txn.Exec(query); err != nil {
 		return 0, errors.Wrap(err, "failed to execute query")
 	}
@@ -181,3 +314,11 @@ func (d *myDumper) insertIntoTable(txn *sql.Tx, tableName string, rowChan <-chan
 func (d *myDumper) quoteIdentifier(name string) string {
 	return fmt.Sprintf("`%s`", strings.Replace(name, "`", "``", -1))
 }
+
+func boolValue(v bool) string {
+	if v {
+		return "1"
+	}
+
+	return "0"
+}
@@ -0,0 +1,285 @@
+package mysql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Dialect returns the mysql dialect.
+func (d *myDumper) Dialect() string {
+	return database.MySQL
+}
+
+// DumpSchema creates the tables of a schema read from another database engine.
+func (d *myDumper) DumpSchema(schema *database.Schema) error {
+	for _, table := range schema.Tables {
+		log.WithField("table", table.Name).Debug("creating table")
+		if _, err := d.conn.Exec(d.createTable(table)); err != nil {
+			return errors.Wrapf(err, "failed to create table %s", table.Name)
+		}
+	}
+
+	return nil
+}
+
+// createTable returns the statement creating the table, indexed columns get a bounded type.
+func (d *myDumper) createTable(table *database.TableSchema) string {
+	definitions := make([]string, 0, len(table.Columns)+len(table.Indexes)+1)
+	for _, column := range table.Columns {
+		definitions = append(definitions, d.columnDefinition(column, table.IsPrimaryKey(column.Name) || table.IsIndexed(column.Name)))
+	}
+
+	if len(table.PrimaryKey) > 0 {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", d.quoteColumns(table.PrimaryKey)))
+	}
+
+	for _, index := range table.Indexes {
+		if column := unindexable(table, index); column != "" {
+			log.WithField("table", table.Name).WithField("index", index.Name).Warnf("mysql cannot index the %s column, the index is not created", column)
+			continue
+		}
+
+		key := "KEY"
+		if index.Unique {
+			key = "UNIQUE KEY"
+		}
+		definitions = append(definitions, fmt.Sprintf("%s %s (%s)", key, d.quoteIdentifier(index.Name), d.quoteColumns(index.Columns)))
+	}
+
+	return fmt.Sprintf(
+		"CREATE TABLE %s (\n  %s\n) DEFAULT CHARSET=utf8mb4;",
+		d.quoteIdentifier(table.Name),
+		strings.Join(definitions, ",\n  "),
+	)
+}
+
+// unindexable returns the first column of the index that mysql cannot index, e.g. a json column.
+func unindexable(table *database.TableSchema, index *database.Index) string {
+	for _, name := range index.Columns {
+		if column := table.Column(name); column != nil && column.Type == database.TypeJSON {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (d *myDumper) quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = d.quoteIdentifier(column)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// columnDefinition returns the definition of the column in a CREATE TABLE statement.
+func (d *myDumper) columnDefinition(column *database.Column, isKey bool) string {
+	definition := fmt.Sprintf("%s %s", d.quoteIdentifier(column.Name), columnType(column, isKey))
+
+	if !column.Nullable {
+		definition += " NOT NULL"
+	}
+
+	// Only key columns can be auto incremented
+	if column.AutoIncrement && isKey {
+		switch column.Type {
+		case database.TypeSmallInt, database.TypeInteger, database.TypeBigInt:
+			definition += " AUTO_INCREMENT"
+		}
+	}
+
+	return definition
+}
+
+// columnType returns the mysql type of a column, keys need a bounded length.
+func columnType(column *database.Column, isKey bool) string {
+	switch column.Type {
+	case database.TypeBoolean:
+		return "tinyint(1)"
+	case database.TypeSmallInt:
+		return "smallint"
+	case database.TypeInteger:
+		return "int"
+	case database.TypeBigInt:
+		return "bigint"
+	case database.TypeDecimal:
+		if column.Precision > 0 {
+			return fmt.Sprintf("decimal(%d,%d)", column.Precision, column.Scale)
+		}
+		return "decimal(65,30)"
+	case database.TypeFloat:
+		return "float"
+	case database.TypeDouble:
+		return "double"
+	case database.TypeString:
+		if column.Length > 0 {
+			return fmt.Sprintf("varchar(%d)", column.Length)
+		}
+	case database.TypeBinary:
+		if isKey {
+			return "varbinary(255)"
+		}
+		return "longblob"
+	case database.TypeDate:
+		return "date"
+	case database.TypeTime:
+		return "time(6)"
+	case database.TypeTimestamp:
+		return "datetime(6)"
+	case database.TypeJSON:
+		return "json"
+	case database.TypeUUID:
+		return "char(36)"
+	case database.TypeEnum:
+		values := make([]string, len(column.Values))
+		for i, value := range column.Values {
+			values[i] = "'" + strings.Replace(value, "'", "''", -1) + "'"
+		}
+		return fmt.Sprintf("enum(%s)", strings.Join(values, ","))
+	}
+
+	if isKey {
+		return "varchar(255)"
+	}
+
+	return "longtext"
+}
+
+// convertValue converts a value read from another database engine to a value mysql accepts.
+func convertValue(column *database.Column, value interface{}) interface{} {
+	v, ok := value.(time.Time)
+	if !ok {
+		return value
+	}
+
+	switch column.Type {
+	case database.TypeDate:
+		return v.Format("2006-01-02")
+	case database.TypeTime:
+		return v.Format("15:04:05.999999")
+	}
+
+	// MySQL datetimes have no time zone
+	return v.UTC()
+}
+
+// TransformTable returns the SQL changing the structure of the table as configured.
+// The changed columns keep their attributes, which are read from the table on the target.
+func (d *myDumper) TransformTable(tableName string, transform config.Transform) (string, error) {
+	var columns map[string]*targetColumn
+	if len(transform.Columns) > 0 || len(transform.Cast) > 0 {
+		var err error
+		if columns, err = d.getTargetColumns(tableName); err != nil {
+			return "", err
+		}
+	}
+
+	return d.transformTable(tableName, columns, transform)
+}
+
+// transformTable returns the SQL changing the structure of the table with the given columns.
+// Columns are renamed with CHANGE COLUMN, as RENAME COLUMN needs mysql 8.
+func (d *myDumper) transformTable(tableName string, columns map[string]*targetColumn, transform config.Transform) (string, error) {
+	table := d.quoteIdentifier(tableName)
+
+	var statements []string
+	for _, index := range transform.DropIndexes {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;", table, d.quoteIdentifier(index)))
+	}
+
+	changed := make(map[string]string, len(transform.Columns)+len(transform.Cast))
+	for column, name := range transform.Columns {
+		changed[column] = name
+	}
+	for column := range transform.Cast {
+		changed[column] = transform.ColumnName(column)
+	}
+
+	for _, name := range sortedKeys(changed) {
+		column, ok := columns[name]
+		if !ok {
+			return "", errors.Errorf("column %s of %s does not exist", name, tableName)
+		}
+
+		columnType := column.columnType
+		if cast, ok := transform.Cast[name]; ok {
+			columnType = cast
+		}
+
+		statements = append(statements, fmt.Sprintf(
+			"ALTER TABLE %s CHANGE COLUMN %s %s %s;",
+			table,
+			d.quoteIdentifier(name),
+			d.quoteIdentifier(changed[name]),
+			column.definition(columnType),
+		))
+	}
+
+	if transform.Rename != "" {
+		statements = append(statements, fmt.Sprintf("RENAME TABLE %s TO %s;", table, d.quoteIdentifier(transform.Rename)))
+	}
+
+	return strings.Join(statements, "\n"), nil
+}
+
+// definition returns the definition of the column with the given type, with its collation, NOT NULL,
+// DEFAULT, AUTO_INCREMENT and ON UPDATE attributes.
+func (c *targetColumn) definition(columnType string) string {
+	definition := columnType
+	if columnType == c.columnType && c.collation.Valid {
+		definition += " COLLATE " + c.collation.String
+	}
+
+	if !c.nullable {
+		definition += " NOT NULL"
+	}
+
+	extra := strings.ToLower(c.extra)
+	if c.defaultValue.Valid {
+		definition += " DEFAULT " + c.defaultExpression(extra)
+	}
+
+	if strings.Contains(extra, "auto_increment") {
+		definition += " AUTO_INCREMENT"
+	}
+
+	if i := strings.Index(extra, "on update "); i >= 0 {
+		definition += " " + c.extra[i:]
+	}
+
+	return definition
+}
+
+// defaultExpression returns the default value of the column as written in a column definition,
+// the information schema gives literals without their quotes.
+func (c *targetColumn) defaultExpression(extra string) string {
+	value := c.defaultValue.String
+
+	switch {
+	case strings.HasPrefix(strings.ToUpper(value), "CURRENT_TIMESTAMP"), strings.HasPrefix(value, "b'"):
+		return value
+	case strings.Contains(extra, "default_generated"):
+		// Expressions of mysql 8
+		return "(" + value + ")"
+	}
+
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(value) + "'"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
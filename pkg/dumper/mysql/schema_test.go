@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTable(t *testing.T) {
+	t.Parallel()
+
+	table := &database.TableSchema{
+		Name: "users",
+		Columns: []*database.Column{
+			{Name: "id", Type: database.TypeUUID},
+			{Name: "code", Type: database.TypeText},
+			{Name: "number", Type: database.TypeInteger, AutoIncrement: true},
+			{Name: "active", Type: database.TypeBoolean},
+			{Name: "bio", Type: database.TypeText, Nullable: true},
+			{Name: "status", Type: database.TypeEnum, Values: []string{"new", "done"}},
+			{Name: "created_at", Type: database.TypeTimestamp},
+			{Name: "email", Type: database.TypeText},
+			{Name: "settings", Type: database.TypeJSON},
+		},
+		PrimaryKey: []string{"id", "code"},
+		Indexes: []*database.Index{
+			{Name: "users_email_key", Columns: []string{"email"}, Unique: true},
+			{Name: "users_status_created_at_idx", Columns: []string{"status", "created_at"}},
+			{Name: "users_settings_idx", Columns: []string{"settings"}},
+		},
+	}
+
+	expected := "CREATE TABLE `users` (\n" +
+		"  `id` char(36) NOT NULL,\n" +
+		"  `code` varchar(255) NOT NULL,\n" +
+		"  `number` int NOT NULL,\n" +
+		"  `active` tinyint(1) NOT NULL,\n" +
+		"  `bio` longtext,\n" +
+		"  `status` enum('new','done') NOT NULL,\n" +
+		"  `created_at` datetime(6) NOT NULL,\n" +
+		"  `email` varchar(255) NOT NULL,\n" +
+		"  `settings` json NOT NULL,\n" +
+		"  PRIMARY KEY (`id`, `code`),\n" +
+		"  UNIQUE KEY `users_email_key` (`email`),\n" +
+		"  KEY `users_status_created_at_idx` (`status`, `created_at`)\n" +
+		") DEFAULT CHARSET=utf8mb4;"
+
+	assert.Equal(t, expected, (&myDumper{}).createTable(table))
+}
+
+func TestTransformTable(t *testing.T) {
+	t.Parallel()
+
+	columns := map[string]*targetColumn{
+		"usr_id":     {name: "usr_id", columnType: "int(10) unsigned", extra: "auto_increment"},
+		"usr_name":   {name: "usr_name", columnType: "varchar(100)", nullable: true, collation: sql.NullString{String: "utf8mb4_bin", Valid: true}},
+		"amount":     {name: "amount", columnType: "float", defaultValue: sql.NullString{String: "0", Valid: true}},
+		"note":       {name: "note", columnType: "varchar(20)", defaultValue: sql.NullString{String: "it's", Valid: true}},
+		"updated_at": {name: "updated_at", columnType: "timestamp", defaultValue: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, extra: "on update CURRENT_TIMESTAMP"},
+	}
+
+	tests := []struct {
+		scenario  string
+		transform config.Transform
+		expected  string
+		err       bool
+	}{
+		{
+			scenario: "when the table is transformed",
+			transform: config.Transform{
+				Rename:      "users",
+				Columns:     map[string]string{"usr_id": "id", "usr_name": "name", "amount": "total", "note": "remark"},
+				DropIndexes: []string{"tbl_user_created_at_idx"},
+				Cast:        map[string]string{"amount": "decimal(12,2)", "updated_at": "datetime"},
+			},
+			expected: "ALTER TABLE `tbl_user` DROP INDEX `tbl_user_created_at_idx`;\n" +
+				"ALTER TABLE `tbl_user` CHANGE COLUMN `amount` `total` decimal(12,2) NOT NULL DEFAULT '0';\n" +
+				"ALTER TABLE `tbl_user` CHANGE COLUMN `note` `remark` varchar(20) NOT NULL DEFAULT 'it''s';\n" +
+				"ALTER TABLE `tbl_user` CHANGE COLUMN `updated_at` `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP on update CURRENT_TIMESTAMP;\n" +
+				"ALTER TABLE `tbl_user` CHANGE COLUMN `usr_id` `id` int(10) unsigned NOT NULL AUTO_INCREMENT;\n" +
+				"ALTER TABLE `tbl_user` CHANGE COLUMN `usr_name` `name` varchar(100) COLLATE utf8mb4_bin;\n" +
+				"RENAME TABLE `tbl_user` TO `users`;",
+		},
+		{
+			scenario:  "when a column does not exist",
+			transform: config.Transform{Columns: map[string]string{"usr_email": "email"}},
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			sql, err := (&myDumper{}).transformTable("tbl_user", columns, test.transform)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, sql)
+		})
+	}
+}
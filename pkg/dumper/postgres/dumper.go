@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/hellofresh/klepto/pkg/config"
 	"github.com/hellofresh/klepto/pkg/database"
@@ -17,16 +18,14 @@ import (
 
 type (
 	pgDumper struct {
-		conn   *sql.DB
-		reader reader.Reader
+		conn *sql.DB
 	}
 )
 
 // NewDumper returns a new postgres dumper.
 func NewDumper(conn *sql.DB, rdr reader.Reader) dumper.Dumper {
 	return engine.New(rdr, &pgDumper{
-		conn:   conn,
-		reader: rdr,
+		conn: conn,
 	})
 }
 
@@ -34,6 +33,30 @@ func (d *pgDumper) GetDatabaseName() (string, error) {
 	panic("NOT AVAILABLE")
 }
 
+// GetColumns returns the columns of the target table.
+func (d *pgDumper) GetColumns(tableName string) ([]string, error) {
+	rows, err := d.conn.Query(
+		"SELECT column_name FROM information_schema.columns WHERE table_schema=current_schema() AND table_name=$1 ORDER BY ordinal_position",
+		tableName,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get target columns")
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
 // DumpStructure dump the mysql database structure.
 func (d *pgDumper) DumpStructure(sql string) error {
 	if _, err := d.conn.Exec(sql); err != nil {
@@ -49,13 +72,13 @@ func (d *pgDumper) DumpViewDefinitions(sql string) error {
 }
 
 // DumpTable dumps a postgres table.
-func (d *pgDumper) DumpTable(tableName string, rowChan <-chan database.Row) error {
+func (d *pgDumper) DumpTable(tableName string, rowChan <-chan database.Row, opts engine.TableOpts) error {
 	txn, err := d.conn.Begin()
 	if err != nil {
 		return errors.Wrap(err, "failed to open transaction")
 	}
 
-	insertedRows, err := d.insertIntoTable(txn, tableName, rowChan)
+	insertedRows, err := d.insertIntoTable(txn, tableName, rowChan, opts)
 	if err != nil {
 		defer func() {
 			if err := txn.Rollback(); err != nil {
@@ -91,7 +114,7 @@ func (d *pgDumper) PreDumpTables(tables []string) error {
 	return nil
 }
 
-// PostDumpTables enable triggers on all tables to enforce foreign key constraints
+// PostDumpTables enable triggers on all tables to enforce foreign key constraints and resets their sequences
 func (d *pgDumper) PostDumpTables(tables []string) error {
 	// We can't use `SET session_replication_role = DEFAULT` because multiple connections and stuff
 	for _, tbl := range tables {
@@ -101,7 +124,7 @@ func (d *pgDumper) PostDumpTables(tables []string) error {
 		}
 	}
 
-	return nil
+	return d.resetSequences(tables)
 }
 
 // Close closes the postgres database connection.
@@ -113,19 +136,131 @@ func (d *pgDumper) Close() error {
 	return nil
 }
 
-func (d *pgDumper) insertIntoTable(txn *sql.Tx, tableName string, rowChan <-chan database.Row) (int64, error) {
-	columns, err := d.reader.GetColumns(tableName)
+func (d *pgDumper) insertIntoTable(txn *sql.Tx, tableName string, rowChan <-chan database.Row, opts engine.TableOpts) (int64, error) {
+	columns := opts.Columns
+
+	if opts.SourceSchema != nil {
+		rowChan = engine.ConvertRows(opts.SourceSchema, rowChan, convertValue)
+	}
+
+	switch opts.WriteMode {
+	case config.WriteTruncate:
+		// TRUNCATE fails on tables referenced by foreign keys, even with the triggers disabled
+		if _, err := txn.Exec(fmt.Sprintf("DELETE FROM %s", strconv.Quote(tableName))); err != nil {
+			return 0, errors.Wrap(err, "failed to empty table")
+		}
+	case config.WriteUpsert:
+		return d.mergeIntoTable(txn, tableName, columns, rowChan, true)
+	case config.WriteSkipExisting:
+		return d.mergeIntoTable(txn, tableName, columns, rowChan, false)
+	}
+
+	return d.copyIn(txn, tableName, tableName, columns, rowChan)
+}
+
+// mergeIntoTable copies the rows into a temporary table, then inserts them into the table
+// updating the existing ones when update is set, skipping them otherwise.
+func (d *pgDumper) mergeIntoTable(txn *sql.Tx, tableName string, columns []string, rowChan <-chan database.Row, update bool) (int64, error) {
+	var keys []string
+	if update {
+		var err error
+		if keys, err = d.primaryKey(txn, tableName); err != nil {
+			return 0, err
+		}
+	}
+
+	staging := "klepto_upsert_" + tableName
+	query := fmt.Sprintf("CREATE TEMPORARY TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", strconv.Quote(staging), strconv.Quote(tableName))
+	if _, err := txn.Exec(query); err != nil {
+		return 0, errors.Wrap(err, "failed to create staging table")
+	}
+
+	upserted, err := d.copyIn(txn, tableName, staging, columns, rowChan)
 	if err != nil {
-		return 0, errors.Wrap(err, "failed to get columns")
+		return 0, err
 	}
 
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+	}
+
+	quotedColumns := make([]string, len(columns))
+	var updates []string
+	for i, column := range columns {
+		quotedColumns[i] = strconv.Quote(column)
+		if !isKey[column] {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", quotedColumns[i], quotedColumns[i]))
+		}
+	}
+
+	conflict := "ON CONFLICT DO NOTHING"
+	if update && len(updates) > 0 {
+		quotedKeys := make([]string, len(keys))
+		for i, key := range keys {
+			quotedKeys[i] = strconv.Quote(key)
+		}
+		conflict = fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(quotedKeys, ", "), strings.Join(updates, ", "))
+	}
+
+	query = fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM %s %s",
+		strconv.Quote(tableName),
+		strings.Join(quotedColumns, ", "),
+		strings.Join(quotedColumns, ", "),
+		strconv.Quote(staging),
+		conflict,
+	)
+	if _, err := txn.Exec(query); err != nil {
+		return 0, errors.Wrap(err, "failed to merge rows")
+	}
+
+	return upserted, nil
+}
+
+// primaryKey returns the primary key columns of the table.
+func (d *pgDumper) primaryKey(txn *sql.Tx, tableName string) ([]string, error) {
+	rows, err := txn.Query(
+		`SELECT a.attname FROM pg_index i
+		 JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		 WHERE i.indrelid = $1::regclass AND i.indisprimary
+		 ORDER BY a.attnum`,
+		strconv.Quote(tableName),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get primary key")
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.Errorf("table %s has no primary key to update the rows with", tableName)
+	}
+
+	return keys, nil
+}
+
+// copyIn copies the rows of the table into the target table.
+func (d *pgDumper) copyIn(txn *sql.Tx, tableName string, target string, columns []string, rowChan <-chan database.Row) (int64, error) {
 	logger := log.WithFields(log.Fields{
 		"table":   tableName,
 		"columns": columns,
 	})
 	logger.Debug("preparing copy in")
 
-	stmt, err := txn.Prepare(pq.CopyIn(tableName, columns...))
+	stmt, err := txn.Prepare(pq.CopyIn(target, columns...))
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to prepare copy in")
 	}
@@ -178,9 +313,11 @@ func (d *pgDumper) relationshipConfigToOptions(relationshipsConfig []*config.Rel
 
 	for _, r := range relationshipsConfig {
 		opts = append(opts, &reader.RelationshipOpt{
+			Table:           r.Table,
 			ReferencedTable: r.ReferencedTable,
-			ReferencedKey:   r.ReferencedKey,
-			ForeignKey:      r.ForeignKey,
+			Keys:            r.KeyPairs(),
+			JoinType:        r.JoinType,
+			Alias:           r.Alias,
 		})
 	}
 
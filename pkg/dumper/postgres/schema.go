@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Dialect returns the postgres dialect.
+func (d *pgDumper) Dialect() string {
+	return database.PostgreSQL
+}
+
+// DumpSchema creates the tables of a schema read from another database engine.
+func (d *pgDumper) DumpSchema(schema *database.Schema) error {
+	// Index names are unique per table in other engines, but per schema in postgres
+	indexNames := make(map[string]bool)
+	for _, table := range schema.Tables {
+		log.WithField("table", table.Name).Debug("creating table")
+		if _, err := d.conn.Exec(createTable(table)); err != nil {
+			return errors.Wrapf(err, "failed to create table %s", table.Name)
+		}
+
+		for _, statement := range createIndexes(table, indexNames) {
+			if _, err := d.conn.Exec(statement); err != nil {
+				return errors.Wrapf(err, "failed to create the indexes of %s", table.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// createTable returns the statement creating the table.
+func createTable(table *database.TableSchema) string {
+	definitions := make([]string, 0, len(table.Columns)+1)
+	for _, column := range table.Columns {
+		definitions = append(definitions, columnDefinition(column))
+	}
+
+	if len(table.PrimaryKey) > 0 {
+		keys := make([]string, len(table.PrimaryKey))
+		for i, key := range table.PrimaryKey {
+			keys[i] = strconv.Quote(key)
+		}
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keys, ", ")))
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n  %s\n);", strconv.Quote(table.Name), strings.Join(definitions, ",\n  "))
+}
+
+// createIndexes returns the statements creating the indexes of the table,
+// an index named as an index of another table is prefixed with the table name.
+func createIndexes(table *database.TableSchema, used map[string]bool) []string {
+	statements := make([]string, 0, len(table.Indexes))
+	for _, index := range table.Indexes {
+		name := index.Name
+		if used[name] {
+			name = table.Name + "_" + index.Name
+			log.WithField("table", table.Name).WithField("index", index.Name).Infof("index name is already used, it is created as %s", name)
+		}
+		used[name] = true
+
+		columns := make([]string, len(index.Columns))
+		for i, column := range index.Columns {
+			columns[i] = strconv.Quote(column)
+		}
+
+		unique := ""
+		if index.Unique {
+			unique = "UNIQUE "
+		}
+		statements = append(statements, fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);", unique, strconv.Quote(name), strconv.Quote(table.Name), strings.Join(columns, ", ")))
+	}
+
+	return statements
+}
+
+// columnDefinition returns the definition of the column in a CREATE TABLE statement.
+func columnDefinition(column *database.Column) string {
+	definition := fmt.Sprintf("%s %s", strconv.Quote(column.Name), columnType(column))
+
+	switch column.Type {
+	case database.TypeSmallInt, database.TypeInteger, database.TypeBigInt:
+		if column.AutoIncrement {
+			definition += " GENERATED BY DEFAULT AS IDENTITY"
+		}
+	case database.TypeEnum:
+		// Enums are checked strings, so no type has to be created
+		values := make([]string, len(column.Values))
+		for i, value := range column.Values {
+			values[i] = quoteLiteral(value)
+		}
+		definition += fmt.Sprintf(" CHECK (%s IN (%s))", strconv.Quote(column.Name), strings.Join(values, ", "))
+	}
+
+	if !column.Nullable {
+		definition += " NOT NULL"
+	}
+
+	return definition
+}
+
+// columnType returns the postgres type of a column.
+func columnType(column *database.Column) string {
+	switch column.Type {
+	case database.TypeBoolean:
+		return "boolean"
+	case database.TypeSmallInt:
+		return "smallint"
+	case database.TypeInteger:
+		return "integer"
+	case database.TypeBigInt:
+		return "bigint"
+	case database.TypeDecimal:
+		if column.Precision > 0 {
+			return fmt.Sprintf("numeric(%d,%d)", column.Precision, column.Scale)
+		}
+		return "numeric"
+	case database.TypeFloat:
+		return "real"
+	case database.TypeDouble:
+		return "double precision"
+	case database.TypeString:
+		if column.Length > 0 {
+			return fmt.Sprintf("varchar(%d)", column.Length)
+		}
+		return "text"
+	case database.TypeBinary:
+		return "bytea"
+	case database.TypeDate:
+		return "date"
+	case database.TypeTime:
+		return "time"
+	case database.TypeTimestamp:
+		return "timestamp"
+	case database.TypeJSON:
+		return "jsonb"
+	case database.TypeUUID:
+		return "uuid"
+	}
+
+	return "text"
+}
+
+// convertValue converts a value read from another database engine to a value postgres accepts.
+func convertValue(column *database.Column, value interface{}) interface{} {
+	switch column.Type {
+	case database.TypeBinary:
+		switch v := value.(type) {
+		case []byte:
+			return `\x` + hex.EncodeToString(v)
+		case string:
+			return `\x` + hex.EncodeToString([]byte(v))
+		}
+	case database.TypeBoolean:
+		// BIT(1) columns are read as a single byte
+		if v, ok := value.([]byte); ok && len(v) == 1 && v[0] < 2 {
+			return v[0] == 1
+		}
+	case database.TypeDate, database.TypeTimestamp:
+		// Zero dates are not valid dates
+		switch v := value.(type) {
+		case time.Time:
+			if v.IsZero() {
+				return nil
+			}
+		case []byte:
+			if strings.HasPrefix(string(v), "0000-00-00") {
+				return nil
+			}
+		case string:
+			if strings.HasPrefix(v, "0000-00-00") {
+				return nil
+			}
+		}
+	}
+
+	return value
+}
+
+// quoteLiteral returns a single-quoted string literal.
+func quoteLiteral(value string) string {
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+// TransformTable returns the SQL changing the structure of the table as configured.
+func (d *pgDumper) TransformTable(tableName string, transform config.Transform) (string, error) {
+	table := strconv.Quote(tableName)
+
+	var statements []string
+	for _, index := range transform.DropIndexes {
+		statements = append(statements, fmt.Sprintf("DROP INDEX IF EXISTS %s;", strconv.Quote(index)))
+	}
+
+	for _, column := range sortedKeys(transform.Cast) {
+		statements = append(statements, fmt.Sprintf(
+			"ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;",
+			table,
+			strconv.Quote(column),
+			transform.Cast[column],
+			strconv.Quote(column),
+			transform.Cast[column],
+		))
+	}
+
+	for _, column := range sortedKeys(transform.Columns) {
+		statements = append(statements, fmt.Sprintf(
+			"ALTER TABLE %s RENAME COLUMN %s TO %s;",
+			table,
+			strconv.Quote(column),
+			strconv.Quote(transform.Columns[column]),
+		))
+	}
+
+	if transform.Rename != "" {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", table, strconv.Quote(transform.Rename)))
+	}
+
+	return strings.Join(statements, "\n"), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
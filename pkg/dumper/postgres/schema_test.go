@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTable(t *testing.T) {
+	t.Parallel()
+
+	table := &database.TableSchema{
+		Name: "users",
+		Columns: []*database.Column{
+			{Name: "id", Type: database.TypeBigInt, AutoIncrement: true},
+			{Name: "active", Type: database.TypeBoolean},
+			{Name: "email", Type: database.TypeString, Length: 255, Nullable: true},
+			{Name: "status", Type: database.TypeEnum, Values: []string{"new", "done"}},
+			{Name: "created_at", Type: database.TypeTimestamp},
+		},
+		PrimaryKey: []string{"id"},
+	}
+
+	expected := `CREATE TABLE "users" (
+  "id" bigint GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+  "active" boolean NOT NULL,
+  "email" varchar(255),
+  "status" text CHECK ("status" IN ('new', 'done')) NOT NULL,
+  "created_at" timestamp NOT NULL,
+  PRIMARY KEY ("id")
+);`
+
+	assert.Equal(t, expected, createTable(table))
+}
+
+func TestCreateIndexes(t *testing.T) {
+	t.Parallel()
+
+	used := map[string]bool{"idx_created_at": true}
+	table := &database.TableSchema{
+		Name: "users",
+		Indexes: []*database.Index{
+			{Name: "email", Columns: []string{"email"}, Unique: true},
+			{Name: "idx_created_at", Columns: []string{"status", "created_at"}},
+		},
+	}
+
+	expected := []string{
+		`CREATE UNIQUE INDEX "email" ON "users" ("email");`,
+		`CREATE INDEX "users_idx_created_at" ON "users" ("status", "created_at");`,
+	}
+
+	assert.Equal(t, expected, createIndexes(table, used))
+	assert.True(t, used["email"])
+}
+
+func TestConvertValue(t *testing.T) {
+	t.Parallel()
+
+	binary := &database.Column{Name: "avatar", Type: database.TypeBinary}
+	boolean := &database.Column{Name: "active", Type: database.TypeBoolean}
+	timestamp := &database.Column{Name: "created_at", Type: database.TypeTimestamp}
+
+	assert.Equal(t, `\x0aff`, convertValue(binary, []byte{0x0a, 0xff}))
+	assert.Equal(t, true, convertValue(boolean, []byte{1}))
+	assert.Equal(t, []byte("1"), convertValue(boolean, []byte("1")))
+	assert.Nil(t, convertValue(timestamp, []byte("0000-00-00 00:00:00")))
+	assert.Nil(t, convertValue(timestamp, time.Time{}))
+	assert.Equal(t, []byte("2024-01-02 03:04:05"), convertValue(timestamp, []byte("2024-01-02 03:04:05")))
+}
+
+func TestTransformTable(t *testing.T) {
+	t.Parallel()
+
+	transform := config.Transform{
+		Rename:      "users",
+		Columns:     map[string]string{"usr_name": "name"},
+		DropIndexes: []string{"tbl_user_created_at_idx"},
+		Cast:        map[string]string{"amount": "numeric(12,2)"},
+	}
+
+	expected := `DROP INDEX IF EXISTS "tbl_user_created_at_idx";
+ALTER TABLE "tbl_user" ALTER COLUMN "amount" TYPE numeric(12,2) USING "amount"::numeric(12,2);
+ALTER TABLE "tbl_user" RENAME COLUMN "usr_name" TO "name";
+ALTER TABLE "tbl_user" RENAME TO "users";`
+
+	sql, err := (&pgDumper{}).TransformTable("tbl_user", transform)
+	require.NoError(t, err)
+	assert.Equal(t, expected, sql)
+}
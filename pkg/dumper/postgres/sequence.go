@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ownedSequence is a sequence owned by a serial or identity column.
+type ownedSequence struct {
+	name   string
+	table  string
+	column string
+	min    int64
+}
+
+// resetSequences sets the sequences owned by the columns of the tables to the highest value of their column,
+// so the next insert does not reuse a copied key.
+func (d *pgDumper) resetSequences(tables []string) error {
+	sequences, err := d.ownedSequences(tables)
+	if err != nil {
+		return err
+	}
+
+	for _, sequence := range sequences {
+		log.WithField("sequence", sequence.name).Debug("resetting sequence")
+		if _, err := d.conn.Exec(setvalQuery(sequence)); err != nil {
+			return errors.Wrapf(err, "failed to reset sequence %s", sequence.name)
+		}
+	}
+
+	return nil
+}
+
+// ownedSequences returns the sequences owned by the columns of the tables.
+func (d *pgDumper) ownedSequences(tables []string) ([]ownedSequence, error) {
+	rows, err := d.conn.Query(
+		`SELECT s.oid::regclass::text, t.relname, a.attname, ps.seqmin
+		 FROM pg_class s
+		 JOIN pg_sequence ps ON ps.seqrelid = s.oid
+		 JOIN pg_depend dep ON dep.objid = s.oid AND dep.classid = 'pg_class'::regclass AND dep.refclassid = 'pg_class'::regclass
+		 JOIN pg_class t ON t.oid = dep.refobjid
+		 JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = dep.refobjsubid
+		 WHERE s.relkind = 'S' AND dep.deptype IN ('a', 'i')
+		 AND t.relnamespace = current_schema()::regnamespace AND t.relname = ANY($1)
+		 ORDER BY t.relname, a.attname`,
+		pq.Array(tables),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get owned sequences")
+	}
+	defer rows.Close()
+
+	var sequences []ownedSequence
+	for rows.Next() {
+		var sequence ownedSequence
+		if err := rows.Scan(&sequence.name, &sequence.table, &sequence.column, &sequence.min); err != nil {
+			return nil, err
+		}
+
+		sequences = append(sequences, sequence)
+	}
+
+	return sequences, rows.Err()
+}
+
+// setvalQuery returns the query setting the sequence to the highest value of its column, empty tables restart at the minimum.
+func setvalQuery(sequence ownedSequence) string {
+	return fmt.Sprintf(
+		"SELECT setval(%s, COALESCE(MAX(%s) + 1, %d), false) FROM %s",
+		quoteLiteral(sequence.name),
+		strconv.Quote(sequence.column),
+		sequence.min,
+		strconv.Quote(sequence.table),
+	)
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetvalQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		sequence ownedSequence
+		expected string
+	}{
+		{
+			scenario: "serial column",
+			sequence: ownedSequence{name: "users_id_seq", table: "users", column: "id", min: 1},
+			expected: `SELECT setval('users_id_seq', COALESCE(MAX("id") + 1, 1), false) FROM "users"`,
+		},
+		{
+			scenario: "quoted names",
+			sequence: ownedSequence{name: `"Order's_Id_seq"`, table: "Orders", column: "Id", min: 100},
+			expected: `SELECT setval('"Order''s_Id_seq"', COALESCE(MAX("Id") + 1, 100), false) FROM "Orders"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			assert.Equal(t, test.expected, setvalQuery(test.sequence))
+		})
+	}
+}
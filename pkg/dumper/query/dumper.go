@@ -35,17 +35,39 @@ func (d *textDumper) GetDatabaseName() (string, error) {
 }
 
 // Dump executes the dump stream process.
-func (d *textDumper) Dump(done chan<- struct{}, spec *config.Spec, concurrency int) error {
+func (d *textDumper) Dump(done chan<- struct{}, spec *config.Spec, dumpOpts dumper.DumpOpts) error {
+	if dumpOpts.Incremental {
+		return errors.New("incremental dumps are not supported by the query dumper")
+	}
+
+	if err := dumpOpts.Validate(); err != nil {
+		return err
+	}
+
+	if !spec.Hooks.IsZero() {
+		log.Warn("hooks are not run by the query dumper")
+	}
+
 	tables, err := d.reader.GetTables()
 	if err != nil {
 		return errors.Wrap(err, "failed to get tables")
 	}
 
-	structure, err := d.reader.GetStructure()
-	if err != nil {
-		return errors.Wrap(err, "could not get database structure")
+	if !dumpOpts.DataOnly {
+		structure, err := d.reader.GetStructure()
+		if err != nil {
+			return errors.Wrap(err, "could not get database structure")
+		}
+		io.WriteString(d.output, structure)
+	}
+
+	if dumpOpts.SchemaOnly {
+		go func() {
+			done <- struct{}{}
+		}()
+
+		return nil
 	}
-	io.WriteString(d.output, structure)
 
 	for _, tbl := range tables {
 		var opts reader.ReadTableOpt
@@ -173,9 +195,11 @@ func (d *textDumper) relationshipConfigToOptions(relationshipsConfig []*config.R
 
 	for _, r := range relationshipsConfig {
 		opts = append(opts, &reader.RelationshipOpt{
+			Table:           r.Table,
 			ReferencedTable: r.ReferencedTable,
-			ReferencedKey:   r.ReferencedKey,
-			ForeignKey:      r.ForeignKey,
+			Keys:            r.KeyPairs(),
+			JoinType:        r.JoinType,
+			Alias:           r.Alias,
 		})
 	}
 
@@ -0,0 +1,201 @@
+package incremental
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	typeTime   = "time"
+	typeInt    = "int"
+	typeFloat  = "float"
+	typeString = "string"
+)
+
+type (
+	// State holds the watermarks of the incremental tables saved between steals.
+	State struct {
+		path       string
+		mu         sync.Mutex
+		watermarks map[string]*Watermark
+	}
+
+	// Watermark is the highest value of the incremental column copied from a table.
+	Watermark struct {
+		// Column is the incremental column.
+		Column string `json:"column"`
+		// Type is the type of the value, used to bind it with the right type.
+		Type string `json:"type"`
+		// Value is the value formatted as a string.
+		Value string `json:"value"`
+	}
+
+	stateFile struct {
+		Tables map[string]*Watermark `json:"tables"`
+	}
+)
+
+// Load reads the state file, an empty state is returned when it does not exist yet.
+func Load(path string) (*State, error) {
+	s := &State{path: path, watermarks: make(map[string]*Watermark)}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read state file")
+	}
+
+	var file stateFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode state file %s", path)
+	}
+
+	for table, watermark := range file.Tables {
+		s.watermarks[table] = watermark
+	}
+
+	return s, nil
+}
+
+// Save writes the state file.
+func (s *State) Save() error {
+	s.mu.Lock()
+	content, err := json.MarshalIndent(stateFile{Tables: s.watermarks}, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "failed to encode state")
+	}
+
+	if err := ioutil.WriteFile(s.path, append(content, '\n'), 0644); err != nil {
+		return errors.Wrap(err, "failed to write state file")
+	}
+
+	return nil
+}
+
+// Get returns the value of the watermark of the table column, false when there is none.
+func (s *State) Get(table string, column string) (interface{}, bool, error) {
+	s.mu.Lock()
+	watermark, ok := s.watermarks[table]
+	s.mu.Unlock()
+
+	// A watermark of another column is meaningless
+	if !ok || watermark.Column != column {
+		return nil, false, nil
+	}
+
+	value, err := watermark.Parse()
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "invalid watermark of %s", table)
+	}
+
+	return value, true, nil
+}
+
+// Set stores the watermark of the table column.
+func (s *State) Set(table string, column string, value interface{}) {
+	watermark := NewWatermark(column, value)
+
+	s.mu.Lock()
+	s.watermarks[table] = watermark
+	s.mu.Unlock()
+}
+
+// NewWatermark creates a watermark from a column value read from the database.
+func NewWatermark(column string, value interface{}) *Watermark {
+	w := &Watermark{Column: column, Type: typeString}
+
+	switch v := value.(type) {
+	case time.Time:
+		w.Type, w.Value = typeTime, v.Format(time.RFC3339Nano)
+	case int64:
+		w.Type, w.Value = typeInt, strconv.FormatInt(v, 10)
+	case float64:
+		w.Type, w.Value = typeFloat, strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		w.Value = string(v)
+	case string:
+		w.Value = v
+	}
+
+	return w
+}
+
+// Parse returns the value of the watermark with its type.
+func (w *Watermark) Parse() (interface{}, error) {
+	switch w.Type {
+	case typeTime:
+		return time.Parse(time.RFC3339Nano, w.Value)
+	case typeInt:
+		return strconv.ParseInt(w.Value, 10, 64)
+	case typeFloat:
+		return strconv.ParseFloat(w.Value, 64)
+	case typeString:
+		return w.Value, nil
+	}
+
+	return nil, errors.Errorf("unknown watermark type %q", w.Type)
+}
+
+// Max returns the greatest of two column values, nil values are ignored.
+func Max(a interface{}, b interface{}) interface{} {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	if less(a, b) {
+		return b
+	}
+
+	return a
+}
+
+func less(a interface{}, b interface{}) bool {
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Before(y)
+		}
+	case int64:
+		if y, ok := b.(int64); ok {
+			return x < y
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return x < y
+		}
+	}
+
+	x, y := toString(a), toString(b)
+
+	// Numbers read as text are compared as numbers
+	if xf, err := strconv.ParseFloat(x, 64); err == nil {
+		if yf, err := strconv.ParseFloat(y, 64); err == nil {
+			return xf < yf
+		}
+	}
+
+	return x < y
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+
+	return NewWatermark("", value).Value
+}
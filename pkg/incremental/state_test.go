@@ -0,0 +1,71 @@
+package incremental
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "klepto")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	state, err := Load(path)
+	require.NoError(t, err)
+
+	_, ok, err := state.Get("orders", "updated_at")
+	require.NoError(t, err)
+	assert.False(t, ok, "a missing state file has no watermarks")
+
+	state.Set("orders", "updated_at", updatedAt)
+	state.Set("events", "id", int64(42))
+	state.Set("logs", "created_at", []byte("2024-01-02 03:04:05"))
+	require.NoError(t, state.Save())
+
+	state, err = Load(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		table    string
+		column   string
+		expected interface{}
+		found    bool
+	}{
+		{table: "orders", column: "updated_at", expected: updatedAt, found: true},
+		{table: "events", column: "id", expected: int64(42), found: true},
+		{table: "logs", column: "created_at", expected: "2024-01-02 03:04:05", found: true},
+		{table: "orders", column: "created_at"},
+		{table: "users", column: "updated_at"},
+	}
+
+	for _, test := range tests {
+		value, found, err := state.Get(test.table, test.column)
+		require.NoError(t, err)
+		assert.Equal(t, test.found, found, test.table)
+		assert.Equal(t, test.expected, value, test.table)
+	}
+}
+
+func TestMax(t *testing.T) {
+	t.Parallel()
+
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Second)
+
+	assert.Equal(t, later, Max(earlier, later))
+	assert.Equal(t, later, Max(later, earlier))
+	assert.Equal(t, int64(10), Max(int64(9), int64(10)))
+	assert.Equal(t, []byte("10"), Max([]byte("9"), []byte("10")), "numbers read as text are compared as numbers")
+	assert.Equal(t, []byte("2024-01-02"), Max([]byte("2024-01-02"), []byte("2024-01-01")))
+	assert.Equal(t, int64(1), Max(nil, int64(1)))
+	assert.Equal(t, int64(1), Max(int64(1), nil))
+}
@@ -0,0 +1,138 @@
+package introspector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/hellofresh/klepto/pkg/scanner"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	// Introspector describes a database as a starter configuration.
+	Introspector struct {
+		reader reader.Reader
+		// largeTableRows is the number of rows from which a table is too large to copy, 0 disables the check.
+		largeTableRows uint64
+	}
+
+	// Suggestion is the suggested configuration of a table.
+	Suggestion struct {
+		// Table is the suggested table configuration.
+		Table *config.Table
+		// Rows is the number of rows of the table.
+		Rows uint64
+		// IgnoreReason explains why the table data is ignored.
+		IgnoreReason string
+		// Findings are the columns that look like personal data, in the order of the table columns.
+		Findings []*scanner.Finding
+	}
+)
+
+// appendOnlyRegex matches the names of tables that usually only grow, like logs or audit trails.
+var appendOnlyRegex = regexp.MustCompile(`(^|_)(logs?|audits?|events?|histor(y|ies)|activit(y|ies)|trails?|journals?)$`)
+
+// New creates a new introspector.
+func New(rdr reader.Reader, largeTableRows uint64) *Introspector {
+	return &Introspector{reader: rdr, largeTableRows: largeTableRows}
+}
+
+// Introspect suggests a configuration for every table of the database.
+func (i *Introspector) Introspect() ([]*Suggestion, error) {
+	planner, ok := i.reader.(reader.Planner)
+	if !ok {
+		return nil, errors.New("reader cannot count rows")
+	}
+
+	introspector, ok := i.reader.(reader.Introspector)
+	if !ok {
+		return nil, errors.New("reader cannot read foreign keys")
+	}
+
+	tables, err := i.reader.GetTables()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tables")
+	}
+
+	suggestions := make([]*Suggestion, 0, len(tables))
+	for _, tableName := range tables {
+		log.WithField("table", tableName).Debug("introspecting table")
+
+		rows, err := planner.CountRows(tableName, reader.ReadTableOpt{}, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to count rows of %s", tableName)
+		}
+
+		suggestion := &Suggestion{Table: &config.Table{Name: tableName}, Rows: rows}
+		suggestions = append(suggestions, suggestion)
+
+		suggestion.IgnoreReason = i.ignoreReason(tableName, rows)
+		if suggestion.IgnoreReason != "" {
+			// Only the structure is copied, so there is nothing to join or anonymise
+			suggestion.Table.IgnoreData = true
+			continue
+		}
+
+		keys, err := introspector.GetForeignKeys(tableName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get foreign keys of %s", tableName)
+		}
+
+		for _, key := range keys {
+			// Joining the table to itself would drop the rows without a parent
+			if key.ReferencedTable == tableName {
+				continue
+			}
+
+			relationship := &config.Relationship{ReferencedTable: key.ReferencedTable}
+			for j, column := range key.Columns {
+				relationship.Keys = append(relationship.Keys, &config.RelationshipKey{ForeignKey: column, ReferencedKey: key.ReferencedColumns[j]})
+			}
+
+			// Single column keys are written the short way
+			if len(relationship.Keys) == 1 {
+				relationship.ForeignKey, relationship.ReferencedKey = relationship.Keys[0].ForeignKey, relationship.Keys[0].ReferencedKey
+				relationship.Keys = nil
+			}
+
+			suggestion.Table.Relationships = append(suggestion.Table.Relationships, relationship)
+		}
+
+		columns, err := i.reader.GetColumns(tableName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get columns of %s", tableName)
+		}
+
+		for _, column := range columns {
+			finding := scanner.DetectByName(tableName, column)
+			if finding == nil {
+				continue
+			}
+
+			if suggestion.Table.Anonymise == nil {
+				suggestion.Table.Anonymise = make(map[string]string)
+			}
+			suggestion.Table.Anonymise[column] = finding.Rule
+			suggestion.Findings = append(suggestion.Findings, finding)
+		}
+	}
+
+	return suggestions, nil
+}
+
+// ignoreReason explains why the data of the table should not be copied, empty when it should.
+func (i *Introspector) ignoreReason(tableName string, rows uint64) string {
+	if appendOnlyRegex.MatchString(strings.ToLower(tableName)) {
+		return "table name looks like an append-only log"
+	}
+
+	if i.largeTableRows > 0 && rows >= i.largeTableRows {
+		return fmt.Sprintf("table has %d rows or more", i.largeTableRows)
+	}
+
+	return ""
+}
@@ -0,0 +1,95 @@
+package introspector
+
+import (
+	"testing"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospect(t *testing.T) {
+	t.Parallel()
+
+	rdr := &mockReader{
+		tables: []string{"users", "orders", "audit_logs", "clicks"},
+		columns: map[string][]string{
+			"users":  {"id", "email", "first_name", "manager_id"},
+			"orders": {"id", "user_id", "shop_id", "shop_region", "total"},
+		},
+		rows: map[string]uint64{"users": 10, "orders": 100, "audit_logs": 5, "clicks": 5000},
+		keys: map[string][]*database.ForeignKey{
+			"users": {
+				{Name: "users_manager", Columns: []string{"manager_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}},
+			},
+			"orders": {
+				{Name: "orders_user", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}},
+				{Name: "orders_shop", Columns: []string{"shop_id", "shop_region"}, ReferencedTable: "shops", ReferencedColumns: []string{"id", "region"}},
+			},
+		},
+	}
+
+	suggestions, err := New(rdr, 1000).Introspect()
+	require.NoError(t, err)
+	require.Len(t, suggestions, 4)
+
+	users := suggestions[0]
+	assert.Equal(t, uint64(10), users.Rows)
+	assert.False(t, users.Table.IgnoreData)
+	assert.Empty(t, users.Table.Relationships, "self references are not joined")
+	assert.Equal(t, map[string]string{"email": "EmailAddress", "first_name": "FirstName"}, users.Table.Anonymise)
+	require.Len(t, users.Findings, 2)
+	assert.Equal(t, "email", users.Findings[0].Column)
+
+	orders := suggestions[1]
+	assert.Equal(t, []*config.Relationship{
+		{ForeignKey: "user_id", ReferencedTable: "users", ReferencedKey: "id"},
+		{ReferencedTable: "shops", Keys: []*config.RelationshipKey{
+			{ForeignKey: "shop_id", ReferencedKey: "id"},
+			{ForeignKey: "shop_region", ReferencedKey: "region"},
+		}},
+	}, orders.Table.Relationships)
+	assert.Empty(t, orders.Table.Anonymise)
+
+	audit := suggestions[2]
+	assert.True(t, audit.Table.IgnoreData)
+	assert.Equal(t, "table name looks like an append-only log", audit.IgnoreReason)
+
+	clicks := suggestions[3]
+	assert.True(t, clicks.Table.IgnoreData)
+	assert.Equal(t, "table has 1000 rows or more", clicks.IgnoreReason)
+}
+
+type mockReader struct {
+	reader.Reader
+	tables  []string
+	columns map[string][]string
+	rows    map[string]uint64
+	keys    map[string][]*database.ForeignKey
+}
+
+func (m *mockReader) GetTables() ([]string, error) {
+	return m.tables, nil
+}
+
+func (m *mockReader) GetColumns(table string) ([]string, error) {
+	return m.columns[table], nil
+}
+
+func (m *mockReader) GetForeignKeys(table string) ([]*database.ForeignKey, error) {
+	return m.keys[table], nil
+}
+
+func (m *mockReader) BuildQuery(string, reader.ReadTableOpt, config.Matchers) (string, []interface{}, error) {
+	return "", nil, nil
+}
+
+func (m *mockReader) CountRows(table string, opts reader.ReadTableOpt, matchers config.Matchers) (uint64, error) {
+	return m.rows[table], nil
+}
+
+func (m *mockReader) EstimateRows(table string, opts reader.ReadTableOpt, matchers config.Matchers) (uint64, error) {
+	return m.rows[table], nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,6 +24,8 @@ type (
 		tables []string
 		// columns is a cache variable for tables and there columns in the db
 		columns sync.Map
+		// columnTypes is a cache variable for tables and there column types in the db
+		columnTypes sync.Map
 		// timeout is the sql read operation timeout
 		timeout time.Duration
 	}
@@ -33,14 +36,30 @@ type (
 		GetDatabaseName() (string, error)
 		// GetStructure returns the SQL used to create the database tables
 		GetStructure() (string, error)
+		// GetStructureSections returns the SQL used to create the database tables, split in pre-data and post-data
+		GetStructureSections() (*database.Structure, error)
 		// GetViewDefinitions returns the SQL used to create the database views
 		GetViewDefinitions(*config.Spec) (string, error)
 		// GetTables return a list of all database tables
 		GetTables() ([]string, error)
 		// GetColumns return a list of all columns for a given table
 		GetColumns(string) ([]string, error)
+		// GetForeignKeys returns the foreign keys of a given table
+		GetForeignKeys(string) ([]*database.ForeignKey, error)
+		// GetColumnTypes returns the type information of all columns for a given table
+		GetColumnTypes(string) ([]*database.ColumnType, error)
+		// GetTableSchema returns the dialect neutral description of a given table
+		GetTableSchema(string) (*database.TableSchema, error)
+		// Dialect returns the SQL dialect of the database
+		Dialect() string
 		// QuoteIdentifier returns a quoted instance of a identifier (table, column etc.)
 		QuoteIdentifier(string) string
+		// Placeholder returns the bind parameter of the argument at the given 1-based position
+		Placeholder(position int) string
+		// EstimateRows returns the number of rows the query planner expects a query to return, without running it
+		EstimateRows(ctx context.Context, query string, args []interface{}) (uint64, error)
+		// SamplePercent returns the table expression and the condition reading a reproducible percentage of the rows of a quoted table
+		SamplePercent(table string, columns []string, percent float64, seed int64) (string, string)
 		// Conn return the sql.DB connection
 		Conn() *sql.DB
 		// Close closes the reader resources and releases them.
@@ -83,6 +102,42 @@ func (e *Engine) GetColumns(tableName string) ([]string, error) {
 	return columns.([]string), nil
 }
 
+// GetColumnTypes returns the column types of the specified database table
+func (e *Engine) GetColumnTypes(tableName string) ([]*database.ColumnType, error) {
+	columnTypes, ok := e.columnTypes.Load(tableName)
+	if ok {
+		return columnTypes.([]*database.ColumnType), nil
+	}
+
+	types, err := e.Storage.GetColumnTypes(tableName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get column types")
+	}
+	e.columnTypes.Store(tableName, types)
+
+	return types, nil
+}
+
+// GetSchema returns the description of all tables in the database
+func (e *Engine) GetSchema() (*database.Schema, error) {
+	tables, err := e.GetTables()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tables")
+	}
+
+	schema := &database.Schema{Dialect: e.Dialect()}
+	for _, tableName := range tables {
+		table, err := e.GetTableSchema(tableName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the schema of %s", tableName)
+		}
+
+		schema.Tables = append(schema.Tables, table)
+	}
+
+	return schema, nil
+}
+
 // ReadTable returns a list of all rows in a table
 func (e *Engine) ReadTable(tableName string, rowChan chan<- database.Row, opts reader.ReadTableOpt, matchers config.Matchers) error {
 	defer close(rowChan)
@@ -90,21 +145,9 @@ func (e *Engine) ReadTable(tableName string, rowChan chan<- database.Row, opts r
 	logger := log.WithField("table", tableName)
 	logger.Debug("reading table data")
 
-	if len(opts.Columns) == 0 {
-		columns, err := e.GetColumns(tableName)
-		if err != nil {
-			return errors.Wrap(err, "failed to get columns")
-		}
-		opts.Columns = e.formatColumns(tableName, columns)
-	}
-
-	var (
-		query sq.SelectBuilder
-		err   error
-	)
-	query, err = e.buildQuery(tableName, opts, matchers)
+	query, err := e.readQuery(tableName, opts, matchers)
 	if err != nil {
-		return errors.Wrapf(err, "failed to build query for %s", tableName)
+		return err
 	}
 
 	var rowCount int
@@ -144,34 +187,145 @@ func (e *Engine) ReadTable(tableName string, rowChan chan<- database.Row, opts r
 		break
 	}
 
-	return e.publishRows(rows, rowChan, tableName)
+	return e.publishRows(rows, rowChan, tableName, opts.OnRow)
+}
+
+// BuildQuery returns the SQL and arguments that will be used to read the table
+func (e *Engine) BuildQuery(tableName string, opts reader.ReadTableOpt, matchers config.Matchers) (string, []interface{}, error) {
+	query, err := e.readQuery(tableName, opts, matchers)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return query.ToSql()
+}
+
+// CountRows returns the number of rows that reading the table will return
+func (e *Engine) CountRows(tableName string, opts reader.ReadTableOpt, matchers config.Matchers) (uint64, error) {
+	querySQL, args, err := e.BuildQuery(tableName, opts, matchers)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	var count uint64
+	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS %s", querySQL, e.QuoteIdentifier("rows"))
+	if err := e.Conn().QueryRowContext(ctx, countSQL, args...).Scan(&count); err != nil {
+		return 0, errors.Wrapf(err, "failed to count rows of %s", tableName)
+	}
+
+	return count, nil
+}
+
+// EstimateRows returns the number of rows that reading the table is expected to return, as estimated by the query planner
+func (e *Engine) EstimateRows(tableName string, opts reader.ReadTableOpt, matchers config.Matchers) (uint64, error) {
+	querySQL, args, err := e.BuildQuery(tableName, opts, matchers)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	rows, err := e.Storage.EstimateRows(ctx, querySQL, args)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to estimate rows of %s", tableName)
+	}
+
+	// The limit is not always part of the estimate
+	if opts.Limit > 0 && rows > opts.Limit {
+		rows = opts.Limit
+	}
+
+	return rows, nil
+}
+
+// readQuery builds the query to read the table, selecting all its columns unless specified
+func (e *Engine) readQuery(tableName string, opts reader.ReadTableOpt, matchers config.Matchers) (sq.SelectBuilder, error) {
+	if len(opts.Columns) == 0 {
+		columns, err := e.GetColumns(tableName)
+		if err != nil {
+			return sq.SelectBuilder{}, errors.Wrap(err, "failed to get columns")
+		}
+		opts.Columns = e.formatColumns(tableName, columns)
+	}
+
+	query, err := e.buildQuery(tableName, opts, matchers)
+	if err != nil {
+		return sq.SelectBuilder{}, errors.Wrapf(err, "failed to build query for %s", tableName)
+	}
+
+	return query, nil
 }
 
 // BuildQuery builds the query that will be used to read the table
 func (e *Engine) buildQuery(tableName string, opts reader.ReadTableOpt, matchers map[string]string) (sq.SelectBuilder, error) {
 	var query sq.SelectBuilder
 
-	query = sq.Select(opts.Columns...).From(e.QuoteIdentifier(tableName))
+	from, condition, err := e.sampledTable(tableName, "", opts.Sample)
+	if err != nil {
+		return query, err
+	}
+
+	query = sq.Select(opts.Columns...).From(from)
+	if condition != "" {
+		query = query.Where(condition)
+	}
+
 	for _, r := range opts.Relationships {
-		if r.Table == "" {
-			r.Table = tableName
+		relationship := config.Relationship{ReferencedTable: r.ReferencedTable, Keys: r.Keys, JoinType: r.JoinType, Alias: r.Alias}
+		if err := relationship.Validate(); err != nil {
+			return query, errors.Wrapf(err, "invalid relationship with %s", r.ReferencedTable)
+		}
+
+		table := r.Table
+		if table == "" {
+			table = tableName
+		}
+
+		// Only the rows of the sampled parents are read
+		join, condition, err := e.sampledTable(r.ReferencedTable, r.Alias, r.ReferencedSample)
+		if err != nil {
+			return query, err
+		}
+
+		var on []string
+		for _, key := range r.Keys {
+			on = append(on, fmt.Sprintf("%s = %s", e.FormatColumn(relationship.Name(), key.ReferencedKey), e.FormatColumn(table, key.ForeignKey)))
+		}
+		if condition != "" {
+			on = append(on, condition)
+		}
+
+		join = fmt.Sprintf("%s ON %s", join, strings.Join(on, " AND "))
+		if strings.EqualFold(r.JoinType, config.LeftJoin) {
+			query = query.LeftJoin(join)
+		} else {
+			query = query.Join(join)
 		}
-		query = query.Join(fmt.Sprintf(
-			"%s ON %s.%s = %s.%s",
-			r.ReferencedTable,
-			r.ReferencedTable,
-			r.ReferencedKey,
-			r.Table,
-			r.ForeignKey,
-		))
 	}
 
+	// Only the placeholders bound by klepto are numbered, a ? in the matchers
+	// is left untouched as it may be an operator such as the jsonb ones.
+	var args []interface{}
 	if opts.Match != "" {
+		condition := opts.Match
 		if v, ok := matchers[opts.Match]; ok {
-			query = query.Where(v)
-		} else {
-			query = query.Where(opts.Match)
+			condition = v
 		}
+
+		where, bound, err := config.BindVars(condition, e.Dialect(), opts.Vars, e.Placeholder)
+		if err != nil {
+			return query, errors.Wrapf(err, "failed to bind the parameters of %q", opts.Match)
+		}
+		query = query.Where(where, bound...)
+		args = append(args, bound...)
+	}
+
+	if opts.Watermark != nil {
+		query = query.Where(fmt.Sprintf("%s >= %s", e.FormatColumn(tableName, opts.IncrementalColumn), e.Placeholder(len(args)+1)), opts.Watermark)
 	}
 
 	for k, v := range opts.Sorts {
@@ -185,6 +339,50 @@ func (e *Engine) buildQuery(tableName string, opts reader.ReadTableOpt, matchers
 	return query, nil
 }
 
+// sampledTable returns the expression reading the table, optionally aliased,
+// and the condition its rows must match to be part of the sample.
+func (e *Engine) sampledTable(tableName string, alias string, sample config.Sample) (string, string, error) {
+	table, name := e.QuoteIdentifier(tableName), tableName
+	if alias != "" {
+		name = alias
+	}
+
+	if sample.IsZero() {
+		if alias != "" {
+			return fmt.Sprintf("%s AS %s", table, e.QuoteIdentifier(alias)), "", nil
+		}
+		return table, "", nil
+	}
+
+	if err := sample.Validate(); err != nil {
+		return "", "", errors.Wrapf(err, "invalid sample of %s", tableName)
+	}
+
+	// The rows are sampled by hashing all their columns, so the same seed gives the same sample
+	columns, err := e.GetColumns(tableName)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to get columns")
+	}
+
+	if sample.Percent > 0 {
+		if alias != "" {
+			table = fmt.Sprintf("%s AS %s", table, e.QuoteIdentifier(alias))
+		}
+
+		from, condition := e.SamplePercent(table, e.formatColumns(name, columns), sample.Percent, sample.Seed)
+		return from, condition, nil
+	}
+
+	return fmt.Sprintf(
+		"(SELECT * FROM %s ORDER BY MD5(CONCAT_WS(',', %d, %s)) LIMIT %d) AS %s",
+		table,
+		sample.Seed,
+		strings.Join(e.formatColumns(tableName, columns), ", "),
+		sample.Rows,
+		e.QuoteIdentifier(name),
+	), "", nil
+}
+
 // FormatColumn returns a escaped table+column string
 func (e *Engine) FormatColumn(tableName string, columnName string) string {
 	return fmt.Sprintf(
@@ -194,7 +392,7 @@ func (e *Engine) FormatColumn(tableName string, columnName string) string {
 	)
 }
 
-func (e *Engine) publishRows(rows *sql.Rows, rowChan chan<- database.Row, tableName string) error {
+func (e *Engine) publishRows(rows *sql.Rows, rowChan chan<- database.Row, tableName string, onRow func(database.Row)) error {
 	defer rows.Close()
 
 	columnTypes, err := rows.ColumnTypes()
@@ -213,7 +411,7 @@ func (e *Engine) publishRows(rows *sql.Rows, rowChan chan<- database.Row, tableN
 	nRowsRead := 0
 	nRowsIterated := 0
 	for rows.Next() {
-		nRowsIterated++;
+		nRowsIterated++
 		row := make(database.Row, columnCount)
 		fields := make([]interface{}, columnCount)
 
@@ -231,7 +429,10 @@ func (e *Engine) publishRows(rows *sql.Rows, rowChan chan<- database.Row, tableN
 		}
 
 		nRowsRead++
-		
+
+		if onRow != nil {
+			onRow(row)
+		}
 		rowChan <- row
 	}
 
@@ -248,3 +449,25 @@ func (e *Engine) formatColumns(tableName string, columns []string) []string {
 
 	return formatted
 }
+
+// ScanForeignKeys groups rows of constraint name, column, referenced table and referenced column
+// ordered by constraint into foreign keys.
+func ScanForeignKeys(rows *sql.Rows) ([]*database.ForeignKey, error) {
+	var keys []*database.ForeignKey
+	for rows.Next() {
+		var name, column, referencedTable, referencedColumn string
+		if err := rows.Scan(&name, &column, &referencedTable, &referencedColumn); err != nil {
+			return nil, err
+		}
+
+		if len(keys) == 0 || keys[len(keys)-1].Name != name {
+			keys = append(keys, &database.ForeignKey{Name: name, ReferencedTable: referencedTable})
+		}
+
+		key := keys[len(keys)-1]
+		key.Columns = append(key.Columns, column)
+		key.ReferencedColumns = append(key.ReferencedColumns, referencedColumn)
+	}
+
+	return keys, rows.Err()
+}
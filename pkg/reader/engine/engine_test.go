@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildQuery(t *testing.T) {
+	t.Parallel()
+
+	userKeys := []*config.RelationshipKey{{ForeignKey: "user_id", ReferencedKey: "id"}}
+
+	tests := []struct {
+		scenario string
+		opts     reader.ReadTableOpt
+		matchers config.Matchers
+		expected string
+		args     []interface{}
+	}{
+		{
+			scenario: "when the table is read without options",
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders"`,
+		},
+		{
+			scenario: "when the matcher has parameters",
+			opts:     reader.ReadTableOpt{Match: "Recent", Vars: config.Vars{"since": "2024-01-01"}},
+			matchers: config.Matchers{"Recent": "orders.created_at > :since"},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders" WHERE orders.created_at > $1`,
+			args:     []interface{}{"2024-01-01"},
+		},
+		{
+			scenario: "when rows are read since a watermark",
+			opts:     reader.ReadTableOpt{IncrementalColumn: "id", Watermark: int64(10)},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders" WHERE "orders"."id" >= $1`,
+			args:     []interface{}{int64(10)},
+		},
+		{
+			scenario: "when the matcher has question marks and rows are read since a watermark",
+			opts:     reader.ReadTableOpt{Match: "Tagged", Vars: config.Vars{"since": "2024-01-01"}, IncrementalColumn: "id", Watermark: int64(10)},
+			matchers: config.Matchers{"Tagged": "orders.tags ? 'vip' AND orders.created_at > :since"},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders" WHERE orders.tags ? 'vip' AND orders.created_at > $1 AND "orders"."id" >= $2`,
+			args:     []interface{}{"2024-01-01", int64(10)},
+		},
+		{
+			scenario: "when a percentage is sampled",
+			opts:     reader.ReadTableOpt{Sample: config.Sample{Percent: 10, Seed: 42}},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders" SAMPLE (10, 42) WHERE hash("orders"."id", "orders"."user_id")`,
+		},
+		{
+			scenario: "when a number of rows is sampled",
+			opts:     reader.ReadTableOpt{Sample: config.Sample{Rows: 5}},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM (SELECT * FROM "orders" ORDER BY MD5(CONCAT_WS(',', 0, "orders"."id", "orders"."user_id")) LIMIT 5) AS "orders"`,
+		},
+		{
+			scenario: "when the referenced table is sampled",
+			opts: reader.ReadTableOpt{Relationships: []*reader.RelationshipOpt{
+				{ReferencedTable: "users", Keys: userKeys, ReferencedSample: config.Sample{Percent: 50}},
+			}},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders" JOIN "users" SAMPLE (50, 0) ON "users"."id" = "orders"."user_id" AND hash("users"."id")`,
+		},
+		{
+			scenario: "when the aliased referenced table is sampled",
+			opts: reader.ReadTableOpt{Relationships: []*reader.RelationshipOpt{
+				{ReferencedTable: "users", Keys: userKeys, Alias: "buyer", ReferencedSample: config.Sample{Rows: 2}},
+			}},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders" JOIN (SELECT * FROM "users" ORDER BY MD5(CONCAT_WS(',', 0, "users"."id")) LIMIT 2) AS "buyer" ON "buyer"."id" = "orders"."user_id"`,
+		},
+		{
+			scenario: "when the relationship has a composite key",
+			opts: reader.ReadTableOpt{Relationships: []*reader.RelationshipOpt{
+				{ReferencedTable: "shops", Keys: []*config.RelationshipKey{
+					{ForeignKey: "shop_id", ReferencedKey: "id"},
+					{ForeignKey: "region", ReferencedKey: "region"},
+				}},
+			}},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders" JOIN "shops" ON "shops"."id" = "orders"."shop_id" AND "shops"."region" = "orders"."region"`,
+		},
+		{
+			scenario: "when the table is left joined to itself",
+			opts: reader.ReadTableOpt{Relationships: []*reader.RelationshipOpt{
+				{ReferencedTable: "orders", Keys: []*config.RelationshipKey{{ForeignKey: "parent_id", ReferencedKey: "id"}}, JoinType: "left", Alias: "parent"},
+			}},
+			expected: `SELECT "orders"."id", "orders"."user_id" FROM "orders" LEFT JOIN "orders" AS "parent" ON "parent"."id" = "orders"."parent_id"`,
+		},
+	}
+
+	e := New(&mockStorage{columns: map[string][]string{"orders": {"id", "user_id"}, "users": {"id"}}}, 0)
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			query, args, err := e.BuildQuery("orders", test.opts, test.matchers)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, query)
+			assert.Equal(t, test.args, args)
+		})
+	}
+
+	_, _, err := e.BuildQuery("orders", reader.ReadTableOpt{Sample: config.Sample{Percent: 10, Rows: 5}}, nil)
+	assert.Error(t, err)
+
+	_, _, err = e.BuildQuery("orders", reader.ReadTableOpt{Match: "id = :missing"}, nil)
+	assert.Error(t, err)
+
+	_, _, err = e.BuildQuery("orders", reader.ReadTableOpt{Relationships: []*reader.RelationshipOpt{
+		{ReferencedTable: "users", Keys: userKeys, JoinType: "CROSS"},
+	}}, nil)
+	assert.Error(t, err)
+}
+
+func TestEstimateRows(t *testing.T) {
+	t.Parallel()
+
+	e := New(&mockStorage{columns: map[string][]string{"orders": {"id"}}, estimate: 500}, time.Second)
+
+	rows, err := e.EstimateRows("orders", reader.ReadTableOpt{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(500), rows)
+
+	rows, err = e.EstimateRows("orders", reader.ReadTableOpt{Limit: 100}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), rows, "the estimate is capped by the limit")
+}
+
+type mockStorage struct {
+	columns  map[string][]string
+	estimate uint64
+}
+
+func (m *mockStorage) GetDatabaseName() (string, error) { return "test", nil }
+
+func (m *mockStorage) GetStructure() (string, error) { return "", nil }
+
+func (m *mockStorage) GetStructureSections() (*database.Structure, error) { return nil, nil }
+
+func (m *mockStorage) GetViewDefinitions(*config.Spec) (string, error) { return "", nil }
+
+func (m *mockStorage) GetTables() ([]string, error) { return nil, nil }
+
+func (m *mockStorage) GetColumns(table string) ([]string, error) { return m.columns[table], nil }
+
+func (m *mockStorage) GetForeignKeys(string) ([]*database.ForeignKey, error) { return nil, nil }
+
+func (m *mockStorage) GetTableSchema(string) (*database.TableSchema, error) { return nil, nil }
+
+func (m *mockStorage) GetColumnTypes(string) ([]*database.ColumnType, error) { return nil, nil }
+
+func (m *mockStorage) Dialect() string { return "test" }
+
+func (m *mockStorage) QuoteIdentifier(name string) string { return fmt.Sprintf("%q", name) }
+
+func (m *mockStorage) Placeholder(position int) string { return fmt.Sprintf("$%d", position) }
+
+func (m *mockStorage) SamplePercent(table string, columns []string, percent float64, seed int64) (string, string) {
+	return fmt.Sprintf("%s SAMPLE (%v, %d)", table, percent, seed), fmt.Sprintf("hash(%s)", strings.Join(columns, ", "))
+}
+
+func (m *mockStorage) EstimateRows(context.Context, string, []interface{}) (uint64, error) {
+	return m.estimate, nil
+}
+
+func (m *mockStorage) Conn() *sql.DB { return nil }
+
+func (m *mockStorage) Close() error { return nil }
@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// explainRow is the part of a row of EXPLAIN used to estimate the rows of a query.
+type explainRow struct {
+	selectType string
+	rows       float64
+	filtered   float64
+}
+
+// EstimateRows returns the number of rows the optimizer expects the query to return, without running it.
+func (s *storage) EstimateRows(ctx context.Context, query string, args []interface{}) (uint64, error) {
+	rows, err := s.conn.QueryContext(ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	var plan []explainRow
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return 0, err
+		}
+
+		row := explainRow{filtered: 100}
+		for i, column := range columns {
+			switch strings.ToLower(column) {
+			case "select_type":
+				row.selectType = values[i].String
+			case "rows":
+				row.rows, _ = strconv.ParseFloat(values[i].String, 64)
+			case "filtered":
+				if values[i].Valid {
+					row.filtered, _ = strconv.ParseFloat(values[i].String, 64)
+				}
+			}
+		}
+		plan = append(plan, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return estimateRows(plan)
+}
+
+// estimateRows multiplies the rows expected from each table joined by the outer query.
+func estimateRows(plan []explainRow) (uint64, error) {
+	estimate := 1.0
+	found := false
+	for _, row := range plan {
+		if row.selectType != "SIMPLE" && row.selectType != "PRIMARY" {
+			continue
+		}
+
+		estimate *= row.rows * row.filtered / 100
+		found = true
+	}
+
+	if !found {
+		return 0, errors.New("empty query plan")
+	}
+
+	return uint64(math.Round(estimate)), nil
+}
@@ -0,0 +1,48 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateRows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		plan     []explainRow
+		expected uint64
+	}{
+		{
+			scenario: "when a table is filtered",
+			plan:     []explainRow{{selectType: "SIMPLE", rows: 2000, filtered: 10}},
+			expected: 200,
+		},
+		{
+			scenario: "when a table is joined",
+			plan: []explainRow{
+				{selectType: "SIMPLE", rows: 100, filtered: 100},
+				{selectType: "SIMPLE", rows: 1, filtered: 50},
+			},
+			expected: 50,
+		},
+		{
+			scenario: "when a sampled table is read from a derived table",
+			plan: []explainRow{
+				{selectType: "PRIMARY", rows: 5, filtered: 100},
+				{selectType: "DERIVED", rows: 2000, filtered: 100},
+			},
+			expected: 5,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			rows, err := estimateRows(test.plan)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, rows)
+		})
+	}
+}
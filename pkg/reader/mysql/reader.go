@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"database/sql"
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
-	"sort"
 
 	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
 	"github.com/hellofresh/klepto/pkg/reader"
 	"github.com/hellofresh/klepto/pkg/reader/engine"
 	"github.com/pkg/errors"
@@ -17,7 +19,7 @@ import (
 
 const (
 	baseTable = "BASE TABLE"
-	view = "VIEW"
+	view      = "VIEW"
 )
 
 type (
@@ -26,7 +28,7 @@ type (
 	}
 
 	wView struct {
-		name string
+		name   string
 		weight int
 	}
 )
@@ -128,6 +130,23 @@ func (s *storage) GetColumns(tableName string) ([]string, error) {
 	return columns, nil
 }
 
+// GetForeignKeys returns the foreign keys of the table.
+func (s *storage) GetForeignKeys(tableName string) ([]*database.ForeignKey, error) {
+	rows, err := s.conn.Query(
+		"SELECT `constraint_name`, `column_name`, `referenced_table_name`, `referenced_column_name` "+
+			"FROM `information_schema`.`key_column_usage` "+
+			"WHERE table_schema=DATABASE() AND table_name=? AND referenced_table_name IS NOT NULL "+
+			"ORDER BY `constraint_name`, `ordinal_position`",
+		tableName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return engine.ScanForeignKeys(rows)
+}
+
 // GetStructure dumps the mysql database structure.
 func (s *storage) GetStructure() (string, error) {
 	tables, err := s.GetTables()
@@ -196,9 +215,9 @@ func (s *storage) GetViewDefinitions(spec *config.Spec) (string, error) {
 	buf := bytes.NewBufferString(preamble)
 	for _, weightedView := range weightedViews {
 		var viewName, viewStmt, query string
-		
+
 		viewName = weightedView.name
-		
+
 		query = fmt.Sprintf("SELECT VIEW_DEFINITION FROM information_schema.VIEWS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = '%s'", viewName)
 		err := s.conn.QueryRow(query).Scan(&viewStmt)
 		if err != nil {
@@ -217,6 +236,21 @@ func (s *storage) QuoteIdentifier(name string) string {
 	return fmt.Sprintf("`%s`", strings.Replace(name, "`", "``", -1))
 }
 
+// SamplePercent keeps the rows whose hashed columns fall in the percentage.
+func (s *storage) SamplePercent(table string, columns []string, percent float64, seed int64) (string, string) {
+	return table, fmt.Sprintf(
+		"CRC32(CONCAT_WS(',', %d, %s)) %% 10000 < %d",
+		seed,
+		strings.Join(columns, ", "),
+		int64(math.Round(percent*100)),
+	)
+}
+
+// Placeholder returns the question mark ? placeholder.
+func (s *storage) Placeholder(position int) string {
+	return "?"
+}
+
 // Close closes the mysql database connection.
 func (s *storage) Close() error {
 	err := s.conn.Close()
@@ -0,0 +1,258 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// columnInfo is a row of information_schema.columns.
+type columnInfo struct {
+	name       string
+	dataType   string
+	columnType string
+	length     sql.NullInt64
+	precision  sql.NullInt64
+	scale      sql.NullInt64
+	nullable   string
+	extra      string
+}
+
+// Dialect returns the mysql dialect.
+func (s *storage) Dialect() string {
+	return database.MySQL
+}
+
+// GetTableSchema returns the dialect neutral description of the table.
+func (s *storage) GetTableSchema(tableName string) (*database.TableSchema, error) {
+	rows, err := s.conn.Query(
+		"SELECT `column_name`, `data_type`, `column_type`, `character_maximum_length`, `numeric_precision`, `numeric_scale`, `is_nullable`, `extra` "+
+			"FROM `information_schema`.`columns` WHERE table_schema=DATABASE() AND table_name=? ORDER BY `ordinal_position`",
+		tableName,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get columns")
+	}
+	defer rows.Close()
+
+	table := &database.TableSchema{Name: tableName}
+	for rows.Next() {
+		var info columnInfo
+		if err := rows.Scan(&info.name, &info.dataType, &info.columnType, &info.length, &info.precision, &info.scale, &info.nullable, &info.extra); err != nil {
+			return nil, err
+		}
+
+		table.Columns = append(table.Columns, toColumn(info))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	keyRows, err := s.conn.Query(
+		"SELECT `column_name` FROM `information_schema`.`key_column_usage` "+
+			"WHERE table_schema=DATABASE() AND table_name=? AND constraint_name='PRIMARY' ORDER BY `ordinal_position`",
+		tableName,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get primary key")
+	}
+	defer keyRows.Close()
+
+	for keyRows.Next() {
+		var key string
+		if err := keyRows.Scan(&key); err != nil {
+			return nil, err
+		}
+
+		table.PrimaryKey = append(table.PrimaryKey, key)
+	}
+
+	if err := keyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return table, s.addIndexes(table)
+}
+
+// addIndexes adds the unique and secondary indexes of the table,
+// full text, spatial and functional indexes are left out as other engines cannot create them.
+func (s *storage) addIndexes(table *database.TableSchema) error {
+	rows, err := s.conn.Query(
+		"SELECT `index_name`, `non_unique`, `index_type`, `column_name` FROM `information_schema`.`statistics` "+
+			"WHERE table_schema=DATABASE() AND table_name=? AND index_name<>'PRIMARY' ORDER BY `index_name`, `seq_in_index`",
+		table.Name,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get indexes")
+	}
+	defer rows.Close()
+
+	unsupported := make(map[string]bool)
+	for rows.Next() {
+		var (
+			name, indexType string
+			nonUnique       int
+			column          sql.NullString
+		)
+		if err := rows.Scan(&name, &nonUnique, &indexType, &column); err != nil {
+			return err
+		}
+
+		if (indexType != "BTREE" && indexType != "HASH") || !column.Valid {
+			unsupported[name] = true
+			continue
+		}
+
+		table.AddIndexColumn(name, nonUnique == 0, column.String)
+	}
+
+	for name := range unsupported {
+		log.WithField("table", table.Name).WithField("index", name).Warn("index cannot be copied across database engines, it is not created")
+		table.RemoveIndex(name)
+	}
+
+	return rows.Err()
+}
+
+// GetColumnTypes returns the type information of the columns of the table.
+// The types are read from the information schema, as the mysql driver doesn't report them.
+func (s *storage) GetColumnTypes(tableName string) ([]*database.ColumnType, error) {
+	rows, err := s.conn.Query(
+		"SELECT `column_name`, `data_type`, `column_type`, `character_maximum_length` "+
+			"FROM `information_schema`.`columns` WHERE table_schema=DATABASE() AND table_name=? ORDER BY `ordinal_position`",
+		tableName,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get column types")
+	}
+	defer rows.Close()
+
+	var types []*database.ColumnType
+	for rows.Next() {
+		var info columnInfo
+		if err := rows.Scan(&info.name, &info.dataType, &info.columnType, &info.length); err != nil {
+			return nil, err
+		}
+
+		types = append(types, toColumnType(info))
+	}
+
+	return types, rows.Err()
+}
+
+// toColumnType maps a mysql column to its type information, unsigned types are prefixed with UNSIGNED.
+func toColumnType(info columnInfo) *database.ColumnType {
+	databaseType := strings.ToUpper(info.dataType)
+	if strings.Contains(strings.ToLower(info.columnType), "unsigned") {
+		databaseType = "UNSIGNED " + databaseType
+	}
+
+	return &database.ColumnType{
+		Name:         info.name,
+		DatabaseType: databaseType,
+		Length:       info.length.Int64,
+	}
+}
+
+// toColumn maps a mysql column to its dialect neutral description.
+func toColumn(info columnInfo) *database.Column {
+	column := &database.Column{
+		Name:          info.name,
+		Type:          database.TypeText,
+		Nullable:      info.nullable == "YES",
+		AutoIncrement: strings.Contains(strings.ToLower(info.extra), "auto_increment"),
+	}
+
+	columnType := strings.ToLower(info.columnType)
+	unsigned := strings.Contains(columnType, "unsigned")
+
+	switch strings.ToLower(info.dataType) {
+	case "tinyint":
+		column.Type = database.TypeSmallInt
+		if strings.HasPrefix(columnType, "tinyint(1)") {
+			column.Type = database.TypeBoolean
+		}
+	case "bit":
+		column.Type = database.TypeBinary
+		if columnType == "bit(1)" {
+			column.Type = database.TypeBoolean
+		}
+	case "smallint", "year":
+		column.Type = database.TypeSmallInt
+		if unsigned {
+			column.Type = database.TypeInteger
+		}
+	case "mediumint":
+		column.Type = database.TypeInteger
+	case "int", "integer":
+		column.Type = database.TypeInteger
+		if unsigned {
+			column.Type = database.TypeBigInt
+		}
+	case "bigint":
+		column.Type = database.TypeBigInt
+		if unsigned {
+			column.Type, column.Precision = database.TypeDecimal, 20
+		}
+	case "decimal", "numeric":
+		column.Type, column.Precision, column.Scale = database.TypeDecimal, info.precision.Int64, info.scale.Int64
+	case "float":
+		column.Type = database.TypeFloat
+	case "double", "real":
+		column.Type = database.TypeDouble
+	case "char", "varchar":
+		column.Type, column.Length = database.TypeString, info.length.Int64
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		column.Type = database.TypeBinary
+	case "date":
+		column.Type = database.TypeDate
+	case "time":
+		column.Type = database.TypeTime
+	case "datetime", "timestamp":
+		column.Type = database.TypeTimestamp
+	case "json":
+		column.Type = database.TypeJSON
+	case "enum":
+		column.Type, column.Values = database.TypeEnum, enumValues(info.columnType)
+	}
+
+	return column
+}
+
+// enumValues returns the values of an enum column type, e.g. enum('a','b').
+func enumValues(columnType string) []string {
+	start, end := strings.Index(columnType, "("), strings.LastIndex(columnType, ")")
+	if start < 0 || end < start {
+		return nil
+	}
+
+	var (
+		values  []string
+		value   strings.Builder
+		quoted  bool
+		content = columnType[start+1 : end]
+	)
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\'' && quoted && i+1 < len(content) && content[i+1] == '\'':
+			// A doubled quote is a quote of the value
+			value.WriteByte(c)
+			i++
+		case c == '\'' && quoted:
+			values = append(values, value.String())
+			value.Reset()
+			quoted = false
+		case c == '\'':
+			quoted = true
+		case quoted:
+			value.WriteByte(c)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToColumn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		info     columnInfo
+		expected *database.Column
+	}{
+		{
+			scenario: "when the column is an auto incremented key",
+			info:     columnInfo{name: "id", dataType: "int", columnType: "int(10) unsigned", nullable: "NO", extra: "auto_increment"},
+			expected: &database.Column{Name: "id", Type: database.TypeBigInt, AutoIncrement: true},
+		},
+		{
+			scenario: "when the column is a tinyint(1)",
+			info:     columnInfo{name: "active", dataType: "tinyint", columnType: "tinyint(1)", nullable: "YES"},
+			expected: &database.Column{Name: "active", Type: database.TypeBoolean, Nullable: true},
+		},
+		{
+			scenario: "when the column is a varchar",
+			info:     columnInfo{name: "email", dataType: "varchar", columnType: "varchar(255)", length: sql.NullInt64{Int64: 255, Valid: true}, nullable: "NO"},
+			expected: &database.Column{Name: "email", Type: database.TypeString, Length: 255},
+		},
+		{
+			scenario: "when the column is a datetime",
+			info:     columnInfo{name: "created_at", dataType: "datetime", columnType: "datetime", nullable: "NO"},
+			expected: &database.Column{Name: "created_at", Type: database.TypeTimestamp},
+		},
+		{
+			scenario: "when the column is a decimal",
+			info: columnInfo{
+				name:       "total",
+				dataType:   "decimal",
+				columnType: "decimal(10,2)",
+				precision:  sql.NullInt64{Int64: 10, Valid: true},
+				scale:      sql.NullInt64{Int64: 2, Valid: true},
+				nullable:   "NO",
+			},
+			expected: &database.Column{Name: "total", Type: database.TypeDecimal, Precision: 10, Scale: 2},
+		},
+		{
+			scenario: "when the column is an enum",
+			info:     columnInfo{name: "status", dataType: "enum", columnType: "enum('new','it''s done')", nullable: "NO"},
+			expected: &database.Column{Name: "status", Type: database.TypeEnum, Values: []string{"new", "it's done"}},
+		},
+		{
+			scenario: "when the column type is unknown",
+			info:     columnInfo{name: "tags", dataType: "set", columnType: "set('a','b')", nullable: "YES"},
+			expected: &database.Column{Name: "tags", Type: database.TypeText, Nullable: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			assert.Equal(t, test.expected, toColumn(test.info))
+		})
+	}
+}
+
+func TestToColumnType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		info     columnInfo
+		expected *database.ColumnType
+	}{
+		{
+			scenario: "when the column is signed",
+			info:     columnInfo{name: "quantity", dataType: "tinyint", columnType: "tinyint(4)"},
+			expected: &database.ColumnType{Name: "quantity", DatabaseType: "TINYINT"},
+		},
+		{
+			scenario: "when the column is unsigned",
+			info:     columnInfo{name: "id", dataType: "int", columnType: "int(10) unsigned"},
+			expected: &database.ColumnType{Name: "id", DatabaseType: "UNSIGNED INT"},
+		},
+		{
+			scenario: "when the column has a length",
+			info:     columnInfo{name: "email", dataType: "varchar", columnType: "varchar(255)", length: sql.NullInt64{Int64: 255, Valid: true}},
+			expected: &database.ColumnType{Name: "email", DatabaseType: "VARCHAR", Length: 255},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			assert.Equal(t, test.expected, toColumnType(test.info))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+)
+
+var (
+	// keyRegex matches the secondary keys of a CREATE TABLE statement, with their first column.
+	keyRegex = regexp.MustCompile("^(?:UNIQUE |FULLTEXT |SPATIAL )?KEY `(?:[^`]|``)*` \\(`((?:[^`]|``)*)`")
+	// autoIncrementRegex matches the auto incremented column of a CREATE TABLE statement.
+	autoIncrementRegex = regexp.MustCompile("^`((?:[^`]|``)*)` .*AUTO_INCREMENT")
+)
+
+// GetStructureSections returns the tables without their secondary keys and foreign keys, which are created by the post-data.
+func (s *storage) GetStructureSections() (*database.Structure, error) {
+	tables, err := s.GetTables()
+	if err != nil {
+		return nil, err
+	}
+
+	preamble, err := s.getPreamble()
+	if err != nil {
+		return nil, err
+	}
+
+	structure := &database.Structure{PostData: make(map[string][]string)}
+	buf := strings.Builder{}
+	buf.WriteString(preamble)
+	buf.WriteString("SET FOREIGN_KEY_CHECKS=0;\n")
+	for _, tableName := range tables {
+		var stmtTableName, tableStmt string
+		err := s.conn.QueryRow(fmt.Sprintf("SHOW CREATE TABLE %s", s.QuoteIdentifier(tableName))).Scan(&stmtTableName, &tableStmt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the structure of %s", tableName)
+		}
+
+		createTable, keys, foreignKeys := splitCreateTable(tableStmt)
+		buf.WriteString(createTable)
+		buf.WriteString(";\n")
+
+		if len(keys) > 0 {
+			structure.PostData[tableName] = []string{alterTable(s.QuoteIdentifier(tableName), keys)}
+		}
+
+		// The copied rows may reference rows that were not copied
+		if len(foreignKeys) > 0 {
+			structure.ForeignKeys = append(
+				structure.ForeignKeys,
+				"SET FOREIGN_KEY_CHECKS=0;\n"+alterTable(s.QuoteIdentifier(tableName), foreignKeys)+"\nSET FOREIGN_KEY_CHECKS=1;",
+			)
+		}
+	}
+	buf.WriteString("SET FOREIGN_KEY_CHECKS=1;")
+	structure.PreData = buf.String()
+
+	return structure, nil
+}
+
+// splitCreateTable removes the secondary keys and the foreign keys from a CREATE TABLE statement.
+// The keys of auto incremented columns are kept, as they cannot exist without one.
+func splitCreateTable(stmt string) (string, []string, []string) {
+	lines := strings.Split(stmt, "\n")
+	if len(lines) < 3 {
+		return stmt, nil, nil
+	}
+
+	definitions := lines[1 : len(lines)-1]
+	autoIncrement := make(map[string]bool)
+	for _, line := range definitions {
+		if match := autoIncrementRegex.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			autoIncrement[match[1]] = true
+		}
+	}
+
+	var kept, keys, foreignKeys []string
+	for _, line := range definitions {
+		definition := strings.TrimSuffix(strings.TrimSpace(line), ",")
+
+		if strings.HasPrefix(definition, "CONSTRAINT ") && strings.Contains(definition, " FOREIGN KEY ") {
+			foreignKeys = append(foreignKeys, definition)
+			continue
+		}
+
+		if match := keyRegex.FindStringSubmatch(definition); match != nil && !autoIncrement[match[1]] {
+			keys = append(keys, definition)
+			continue
+		}
+
+		kept = append(kept, "  "+definition)
+	}
+
+	createTable := lines[0] + "\n" + strings.Join(kept, ",\n") + "\n" + lines[len(lines)-1]
+
+	return createTable, keys, foreignKeys
+}
+
+// alterTable returns the statement adding the definitions to the table.
+func alterTable(table string, definitions []string) string {
+	adds := make([]string, len(definitions))
+	for i, definition := range definitions {
+		adds[i] = "ADD " + definition
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s %s;", table, strings.Join(adds, ", "))
+}
@@ -0,0 +1,38 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCreateTable(t *testing.T) {
+	t.Parallel()
+
+	stmt := "CREATE TABLE `orders` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `number` int NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` int NOT NULL,\n" +
+		"  `code` varchar(10) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `orders_code` (`code`),\n" +
+		"  KEY `orders_number` (`number`),\n" +
+		"  KEY `orders_user_id` (`user_id`,`code`),\n" +
+		"  CONSTRAINT `orders_user_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+
+	createTable, keys, foreignKeys := splitCreateTable(stmt)
+
+	assert.Equal(t, "CREATE TABLE `orders` (\n"+
+		"  `id` int NOT NULL,\n"+
+		"  `number` int NOT NULL AUTO_INCREMENT,\n"+
+		"  `user_id` int NOT NULL,\n"+
+		"  `code` varchar(10) NOT NULL,\n"+
+		"  PRIMARY KEY (`id`),\n"+
+		"  KEY `orders_number` (`number`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4", createTable, "the key of an auto incremented column is kept")
+	assert.Equal(t, []string{"UNIQUE KEY `orders_code` (`code`)", "KEY `orders_user_id` (`user_id`,`code`)"}, keys)
+	assert.Equal(t, []string{"CONSTRAINT `orders_user_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)"}, foreignKeys)
+
+	assert.Equal(t, "ALTER TABLE `orders` ADD UNIQUE KEY `orders_code` (`code`), ADD KEY `orders_user_id` (`user_id`,`code`);", alterTable("`orders`", keys))
+}
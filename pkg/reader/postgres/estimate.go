@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// EstimateRows returns the number of rows the planner expects the query to return, without running it.
+func (s *storage) EstimateRows(ctx context.Context, query string, args []interface{}) (uint64, error) {
+	var plan []byte
+	if err := s.conn.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&plan); err != nil {
+		return 0, err
+	}
+
+	return planRows(plan)
+}
+
+// planRows returns the rows estimated by the top node of a JSON query plan.
+func planRows(plan []byte) (uint64, error) {
+	var nodes []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		}
+	}
+	if err := json.Unmarshal(plan, &nodes); err != nil {
+		return 0, errors.Wrap(err, "failed to parse the query plan")
+	}
+
+	if len(nodes) == 0 {
+		return 0, errors.New("empty query plan")
+	}
+
+	return uint64(math.Round(nodes[0].Plan.Rows)), nil
+}
@@ -0,0 +1,19 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRows(t *testing.T) {
+	t.Parallel()
+
+	rows, err := planRows([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users", "Plan Rows": 1520, "Plan Width": 36}}]`))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1520), rows)
+
+	_, err = planRows([]byte(`[]`))
+	assert.Error(t, err)
+}
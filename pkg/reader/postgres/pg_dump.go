@@ -30,12 +30,21 @@ func NewPgDump(dsn string) (*PgDump, error) {
 
 // GetStructure executes the pg dump command.
 func (p *PgDump) GetStructure() (string, error) {
+	return p.dump("--schema-only")
+}
+
+// GetSection executes the pg dump command for a section of the structure, either pre-data or post-data.
+func (p *PgDump) GetSection(section string) (string, error) {
+	return p.dump("--section=" + section)
+}
+
+func (p *PgDump) dump(selection string) (string, error) {
 	logger := log.WithField("command", p.command)
 
 	cmd := exec.Command(
 		p.command,
 		"--dbname", p.dsn,
-		"--schema-only",
+		selection,
 		"--no-privileges",
 		"--no-owner",
 	)
@@ -2,10 +2,12 @@ package postgres
 
 import (
 	"database/sql"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
 	"github.com/hellofresh/klepto/pkg/reader"
 	"github.com/hellofresh/klepto/pkg/reader/engine"
 	"github.com/pkg/errors"
@@ -21,6 +23,7 @@ type (
 	// PgDumper executes the pg dump command.
 	PgDumper interface {
 		GetStructure() (stmt string, err error)
+		GetSection(section string) (stmt string, err error)
 	}
 )
 
@@ -68,7 +71,6 @@ func (s *storage) GetViewDefinitions(spec *config.Spec) (string, error) {
 	panic("NOT AVAILABLE")
 }
 
-
 func (s *storage) GetColumns(table string) ([]string, error) {
 	log.WithField("table", table).Debug("fetching table columns")
 	rows, err := s.conn.Query(
@@ -93,11 +95,49 @@ func (s *storage) GetColumns(table string) ([]string, error) {
 	return columns, nil
 }
 
+// GetForeignKeys returns the foreign keys of the table.
+func (s *storage) GetForeignKeys(table string) ([]*database.ForeignKey, error) {
+	log.WithField("table", table).Debug("fetching table foreign keys")
+	rows, err := s.conn.Query(
+		`SELECT c.conname, a.attname, rt.relname, ra.attname
+		 FROM pg_constraint c
+		 JOIN pg_class t ON t.oid = c.conrelid
+		 JOIN pg_class rt ON rt.oid = c.confrelid
+		 CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refattnum, position)
+		 JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+		 JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.refattnum
+		 WHERE c.contype = 'f' AND t.relname = $1 AND pg_table_is_visible(t.oid)
+		 ORDER BY c.conname, k.position`,
+		table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return engine.ScanForeignKeys(rows)
+}
+
 // QuoteIdentifier returns a double-quoted name.
 func (s *storage) QuoteIdentifier(name string) string {
 	return strconv.Quote(name)
 }
 
+// SamplePercent samples the table with a repeatable TABLESAMPLE.
+func (s *storage) SamplePercent(table string, columns []string, percent float64, seed int64) (string, string) {
+	return fmt.Sprintf(
+		"%s TABLESAMPLE BERNOULLI (%s) REPEATABLE (%d)",
+		table,
+		strconv.FormatFloat(percent, 'f', -1, 64),
+		seed,
+	), ""
+}
+
+// Placeholder returns the dollar $1 placeholder of the given position.
+func (s *storage) Placeholder(position int) string {
+	return "$" + strconv.Itoa(position)
+}
+
 // Close closes the postgres connection reader.
 func (s *storage) Close() error {
 	if err := s.conn.Close(); err != nil {
@@ -0,0 +1,254 @@
+package postgres
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// columnInfo is a row of information_schema.columns.
+type columnInfo struct {
+	name       string
+	dataType   string
+	udtName    string
+	length     sql.NullInt64
+	precision  sql.NullInt64
+	scale      sql.NullInt64
+	nullable   string
+	defaultVal sql.NullString
+	identity   string
+}
+
+// Dialect returns the postgres dialect.
+func (s *storage) Dialect() string {
+	return database.PostgreSQL
+}
+
+// GetTableSchema returns the dialect neutral description of the table.
+func (s *storage) GetTableSchema(table string) (*database.TableSchema, error) {
+	log.WithField("table", table).Debug("fetching table schema")
+	rows, err := s.conn.Query(
+		`SELECT column_name, data_type, udt_name, character_maximum_length, numeric_precision, numeric_scale, is_nullable, column_default, is_identity
+		 FROM information_schema.columns
+		 WHERE table_catalog=current_database() AND table_schema=current_schema() AND table_name=$1
+		 ORDER BY ordinal_position`,
+		table,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get columns")
+	}
+	defer rows.Close()
+
+	var infos []columnInfo
+	for rows.Next() {
+		var info columnInfo
+		if err := rows.Scan(&info.name, &info.dataType, &info.udtName, &info.length, &info.precision, &info.scale, &info.nullable, &info.defaultVal, &info.identity); err != nil {
+			return nil, err
+		}
+
+		infos = append(infos, info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	schema := &database.TableSchema{Name: table}
+	for _, info := range infos {
+		column := toColumn(info)
+		if info.dataType == "USER-DEFINED" {
+			if column.Values, err = s.enumValues(info.udtName); err != nil {
+				return nil, err
+			}
+
+			if len(column.Values) > 0 {
+				column.Type = database.TypeEnum
+			}
+		}
+
+		schema.Columns = append(schema.Columns, column)
+	}
+
+	if schema.PrimaryKey, err = s.primaryKey(table); err != nil {
+		return nil, err
+	}
+
+	return schema, s.addIndexes(schema)
+}
+
+// GetColumnTypes returns the type information of the columns of the table.
+func (s *storage) GetColumnTypes(table string) ([]*database.ColumnType, error) {
+	rows, err := s.conn.Query(
+		`SELECT column_name, udt_name, character_maximum_length
+		 FROM information_schema.columns
+		 WHERE table_catalog=current_database() AND table_schema=current_schema() AND table_name=$1
+		 ORDER BY ordinal_position`,
+		table,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get column types")
+	}
+	defer rows.Close()
+
+	var types []*database.ColumnType
+	for rows.Next() {
+		var (
+			name, udtName string
+			length        sql.NullInt64
+		)
+		if err := rows.Scan(&name, &udtName, &length); err != nil {
+			return nil, err
+		}
+
+		// The udt names are the type names the driver reports, e.g. INT4 or VARCHAR
+		types = append(types, &database.ColumnType{Name: name, DatabaseType: strings.ToUpper(udtName), Length: length.Int64})
+	}
+
+	return types, rows.Err()
+}
+
+// enumValues returns the labels of an enum type, none when the type is not an enum.
+func (s *storage) enumValues(typeName string) ([]string, error) {
+	rows, err := s.conn.Query(
+		`SELECT e.enumlabel FROM pg_enum e
+		 JOIN pg_type t ON t.oid = e.enumtypid
+		 WHERE t.typname = $1
+		 ORDER BY e.enumsortorder`,
+		typeName,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the values of %s", typeName)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// addIndexes adds the unique and secondary indexes of the table,
+// partial, expression and non btree indexes are left out as other engines cannot create them.
+func (s *storage) addIndexes(table *database.TableSchema) error {
+	rows, err := s.conn.Query(
+		`SELECT c.relname, i.indisunique, i.indpred IS NOT NULL OR am.amname <> 'btree', a.attname FROM pg_index i
+		 JOIN pg_class c ON c.oid = i.indexrelid
+		 JOIN pg_am am ON am.oid = c.relam
+		 CROSS JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, position)
+		 LEFT JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+		 WHERE i.indrelid = $1::regclass AND NOT i.indisprimary
+		 ORDER BY c.relname, k.position`,
+		strconv.Quote(table.Name),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get indexes")
+	}
+	defer rows.Close()
+
+	unsupported := make(map[string]bool)
+	for rows.Next() {
+		var (
+			name            string
+			unique, special bool
+			column          sql.NullString
+		)
+		if err := rows.Scan(&name, &unique, &special, &column); err != nil {
+			return err
+		}
+
+		if special || !column.Valid {
+			unsupported[name] = true
+			continue
+		}
+
+		table.AddIndexColumn(name, unique, column.String)
+	}
+
+	for name := range unsupported {
+		log.WithField("table", table.Name).WithField("index", name).Warn("index cannot be copied across database engines, it is not created")
+		table.RemoveIndex(name)
+	}
+
+	return rows.Err()
+}
+
+// primaryKey returns the primary key columns of the table.
+func (s *storage) primaryKey(table string) ([]string, error) {
+	rows, err := s.conn.Query(
+		`SELECT a.attname FROM pg_index i
+		 JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		 WHERE i.indrelid = $1::regclass AND i.indisprimary
+		 ORDER BY array_position(i.indkey::int2[], a.attnum)`,
+		strconv.Quote(table),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get primary key")
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// toColumn maps a postgres column to its dialect neutral description.
+func toColumn(info columnInfo) *database.Column {
+	column := &database.Column{
+		Name:          info.name,
+		Type:          database.TypeText,
+		Nullable:      info.nullable == "YES",
+		AutoIncrement: info.identity == "YES" || strings.HasPrefix(info.defaultVal.String, "nextval("),
+	}
+
+	switch info.dataType {
+	case "boolean":
+		column.Type = database.TypeBoolean
+	case "smallint":
+		column.Type = database.TypeSmallInt
+	case "integer":
+		column.Type = database.TypeInteger
+	case "bigint":
+		column.Type = database.TypeBigInt
+	case "numeric":
+		column.Type, column.Precision, column.Scale = database.TypeDecimal, info.precision.Int64, info.scale.Int64
+	case "real":
+		column.Type = database.TypeFloat
+	case "double precision":
+		column.Type = database.TypeDouble
+	case "character varying", "character":
+		column.Type, column.Length = database.TypeString, info.length.Int64
+	case "bytea":
+		column.Type = database.TypeBinary
+	case "date":
+		column.Type = database.TypeDate
+	case "time without time zone", "time with time zone":
+		column.Type = database.TypeTime
+	case "timestamp without time zone", "timestamp with time zone":
+		column.Type = database.TypeTimestamp
+	case "json", "jsonb":
+		column.Type = database.TypeJSON
+	case "uuid":
+		column.Type = database.TypeUUID
+	}
+
+	return column
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/pkg/errors"
+)
+
+var (
+	// postDataTableRegex matches the table of an index, constraint or trigger statement.
+	postDataTableRegex = regexp.MustCompile(`(?is)^(?:ALTER TABLE (?:ONLY )?|CREATE (?:UNIQUE )?INDEX .*? ON (?:ONLY )?|CREATE (?:CONSTRAINT )?TRIGGER .*? ON )((?:"[^"]+"|[\w$]+)(?:\.(?:"[^"]+"|[\w$]+))?)`)
+	// sessionRegex matches the statements pg_dump uses to configure its session.
+	sessionRegex = regexp.MustCompile(`(?i)^(SET |SELECT pg_catalog\.set_config)`)
+)
+
+// GetStructureSections returns the pre-data and the post-data sections of pg_dump.
+func (s *storage) GetStructureSections() (*database.Structure, error) {
+	preData, err := s.GetSection("pre-data")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pre-data")
+	}
+
+	postData, err := s.GetSection("post-data")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get post-data")
+	}
+
+	structure := splitPostData(postData)
+	structure.PreData = preData
+
+	return structure, nil
+}
+
+// splitPostData groups the post-data statements by table, the foreign keys are added without validating the existing rows.
+func splitPostData(postData string) *database.Structure {
+	structure := &database.Structure{PostData: make(map[string][]string)}
+
+	for _, statement := range splitStatements(postData) {
+		if sessionRegex.MatchString(statement) {
+			continue
+		}
+
+		// The copied rows may reference rows that were not copied
+		if strings.Contains(strings.ToUpper(statement), "FOREIGN KEY") {
+			structure.ForeignKeys = append(structure.ForeignKeys, strings.TrimSuffix(statement, ";")+" NOT VALID;")
+			continue
+		}
+
+		match := postDataTableRegex.FindStringSubmatch(statement)
+		if match == nil {
+			structure.ForeignKeys = append(structure.ForeignKeys, statement)
+			continue
+		}
+
+		table := unqualify(match[1])
+		structure.PostData[table] = append(structure.PostData[table], statement)
+	}
+
+	return structure
+}
+
+// splitStatements splits the pg_dump output in statements, without the comments.
+func splitStatements(sql string) []string {
+	var (
+		statements []string
+		current    []string
+	)
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if len(current) == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+
+		current = append(current, line)
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+
+	return statements
+}
+
+// unqualify returns the table name without its schema and quotes.
+func unqualify(name string) string {
+	if strings.HasSuffix(name, `"`) {
+		if start := strings.LastIndex(name[:len(name)-1], `"`); start >= 0 {
+			if unquoted, err := strconv.Unquote(name[start:]); err == nil {
+				return unquoted
+			}
+		}
+	}
+
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		return name[dot+1:]
+	}
+
+	return name
+}
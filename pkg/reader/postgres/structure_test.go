@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPostData(t *testing.T) {
+	t.Parallel()
+
+	postData := `--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+
+--
+-- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+--
+-- Name: orders_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX orders_user_id_idx ON public.orders USING btree (user_id);
+
+CREATE UNIQUE INDEX "Events_code_key" ON public."Events" USING btree (code);
+
+CREATE TRIGGER orders_updated BEFORE UPDATE ON public.orders FOR EACH ROW EXECUTE FUNCTION public.touch();
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id);
+
+CREATE RULE hide AS ON DELETE TO public.users DO INSTEAD NOTHING;
+`
+
+	structure := splitPostData(postData)
+
+	assert.Equal(t, map[string][]string{
+		"users": {"ALTER TABLE ONLY public.users\n    ADD CONSTRAINT users_pkey PRIMARY KEY (id);"},
+		"orders": {
+			"CREATE INDEX orders_user_id_idx ON public.orders USING btree (user_id);",
+			"CREATE TRIGGER orders_updated BEFORE UPDATE ON public.orders FOR EACH ROW EXECUTE FUNCTION public.touch();",
+		},
+		"Events": {`CREATE UNIQUE INDEX "Events_code_key" ON public."Events" USING btree (code);`},
+	}, structure.PostData)
+
+	assert.Equal(t, []string{
+		"ALTER TABLE ONLY public.orders\n    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) NOT VALID;",
+		"CREATE RULE hide AS ON DELETE TO public.users DO INSTEAD NOTHING;",
+	}, structure.ForeignKeys)
+}
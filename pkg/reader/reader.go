@@ -22,12 +22,20 @@ type (
 		GetDatabaseName() (string, error)
 		// GetStructure returns the SQL used to create the database tables
 		GetStructure() (string, error)
+		// GetStructureSections returns the SQL used to create the database tables, split in pre-data and post-data
+		GetStructureSections() (*database.Structure, error)
 		// GetViewDefinitions returns the SQL used to create database views
 		GetViewDefinitions(*config.Spec) (string, error)
 		// GetTables returns a list of all databases tables
 		GetTables() ([]string, error)
 		// GetColumns return a list of all columns for a given table
 		GetColumns(string) ([]string, error)
+		// GetColumnTypes return the type information of all columns for a given table
+		GetColumnTypes(string) ([]*database.ColumnType, error)
+		// GetSchema returns the dialect neutral description of the database tables
+		GetSchema() (*database.Schema, error)
+		// Dialect returns the SQL dialect of the database, e.g. mysql or postgres
+		Dialect() string
 		// FormatColumn returns a escaped table.column string
 		FormatColumn(tableName string, columnName string) string
 		// ReadTable returns a channel with all database rows
@@ -36,6 +44,22 @@ type (
 		Close() error
 	}
 
+	// Planner is implemented by readers that can explain how a table is read without reading it.
+	Planner interface {
+		// BuildQuery returns the SQL and arguments used to read a table
+		BuildQuery(string, ReadTableOpt, config.Matchers) (string, []interface{}, error)
+		// CountRows returns the number of rows that reading a table would return
+		CountRows(string, ReadTableOpt, config.Matchers) (uint64, error)
+		// EstimateRows returns the number of rows that reading a table is expected to return, without reading them
+		EstimateRows(string, ReadTableOpt, config.Matchers) (uint64, error)
+	}
+
+	// Introspector is implemented by readers that can describe the constraints of the tables.
+	Introspector interface {
+		// GetForeignKeys returns the foreign keys of a given table
+		GetForeignKeys(string) ([]*database.ForeignKey, error)
+	}
+
 	// ReadTableOpt represents the read table options
 	ReadTableOpt struct {
 		// Columns contains the (quoted) column of the table
@@ -46,20 +70,34 @@ type (
 		Sorts map[string]string
 		// Limit defines a limit of results to be fetched
 		Limit uint64
+		// Sample reads a random sample of the rows
+		Sample config.Sample
 		// Relationships defines an slice of relationship definitions
 		Relationships []*RelationshipOpt
+		// Vars are the values of the :name parameters of the match condition
+		Vars config.Vars
+		// IncrementalColumn is the column compared with the Watermark
+		IncrementalColumn string
+		// Watermark reads only the rows whose IncrementalColumn is greater or equal, when set
+		Watermark interface{}
+		// OnRow is called with every row as read from the source, before it is anonymised
+		OnRow func(database.Row)
 	}
 
 	// RelationshipOpt represents the relationships options
 	RelationshipOpt struct {
 		// Table is the table name.
 		Table string
-		// ForeignKey is the table name foreign key.
-		ForeignKey string
 		// ReferencedTable is the referenced table name.
 		ReferencedTable string
-		// ReferencedKey is the referenced table primary key name.
-		ReferencedKey string
+		// Keys are the foreign key and referenced key pairs.
+		Keys []*config.RelationshipKey
+		// JoinType is either INNER or LEFT, INNER when empty.
+		JoinType string
+		// Alias is the name the referenced table is joined as.
+		Alias string
+		// ReferencedSample is the sample read from the referenced table, so only rows of sampled parents are read.
+		ReferencedSample config.Sample
 	}
 
 	// ConnOpts are the options to create a connection
@@ -91,3 +129,40 @@ func Connect(opts ConnOpts) (reader Reader, err error) {
 
 	return
 }
+
+// NewReadTableOpt returns the options used to read a table with the given configuration.
+func NewReadTableOpt(table *config.Table, spec *config.Spec) ReadTableOpt {
+	if spec == nil {
+		spec = new(config.Spec)
+	}
+
+	if table == nil {
+		return ReadTableOpt{Vars: spec.Vars}
+	}
+
+	var relationships []*RelationshipOpt
+	for _, r := range table.Relationships {
+		relationship := &RelationshipOpt{
+			Table:           r.Table,
+			ReferencedTable: r.ReferencedTable,
+			Keys:            r.KeyPairs(),
+			JoinType:        r.JoinType,
+			Alias:           r.Alias,
+		}
+
+		if referenced, err := spec.Tables.FindByName(r.ReferencedTable); err == nil {
+			relationship.ReferencedSample = referenced.Filter.Sample
+		}
+
+		relationships = append(relationships, relationship)
+	}
+
+	return ReadTableOpt{
+		Match:         table.Filter.Match,
+		Sorts:         table.Filter.Sorts,
+		Limit:         table.Filter.Limit,
+		Sample:        table.Filter.Sample,
+		Relationships: relationships,
+		Vars:          spec.Vars,
+	}
+}
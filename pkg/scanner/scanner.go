@@ -0,0 +1,296 @@
+package scanner
+
+import (
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// phoneRule is the rule suggested for phone number columns.
+const phoneRule = "Phone"
+
+// matchThreshold is the share of sampled non empty values that must match a pattern to flag a column.
+const matchThreshold = 0.5
+
+type (
+	// Scanner samples the tables of a database looking for columns that contain personal data.
+	Scanner struct {
+		reader reader.Reader
+		// sampleSize is the number of rows sampled from each table.
+		sampleSize uint64
+	}
+
+	// Finding is a column that looks like it contains personal data.
+	Finding struct {
+		// Table is the table name.
+		Table string
+		// Column is the column name.
+		Column string
+		// Reason explains why the column was flagged.
+		Reason string
+		// Rule is the suggested anonymisation rule.
+		Rule string
+	}
+
+	nameRule struct {
+		pattern *regexp.Regexp
+		kind    string
+		rule    string
+	}
+
+	valueRule struct {
+		kind  string
+		rule  string
+		match func(string) bool
+	}
+)
+
+var (
+	// nameRules are checked in order, the first matching column name wins.
+	// The names are matched as whole words of the snake_cased column name, so that ssn doesn't match classname.
+	nameRules = []nameRule{
+		{wordRegex(`e_?mails?`), "email", "EmailAddress"},
+		{wordRegex(`(tele)?phone(_?n(o|um)(ber)?)?|mobile|tel|fax`), "phone number", phoneRule},
+		{wordRegex(`iban|account_?n(o|um)(ber)?|bank_?account`), "bank account", "mask:last=4"},
+		{wordRegex(`ssn|social_?sec(urity)?|national_?id|passport|tax_?id`), "national identifier", "mask:last=2"},
+		{wordRegex(`card_?n(o|um)(ber)?|credit_?card|pan`), "card number", "mask:last=4"},
+		{wordRegex(`first_?name|given_?name|forename`), "first name", "FirstName"},
+		{wordRegex(`last_?name|family_?name|surname`), "last name", "LastName"},
+		{regexp.MustCompile(`(^|_)user_?name($|_)|^login$`), "user name", "UserName"},
+		{regexp.MustCompile(`^(full_?|display_?|contact_?|customer_?)?name$`), "name", "FullName"},
+		{wordRegex(`password|passwd`), "password", "SimplePassword"},
+		{wordRegex(`street|address_?line\d*|address`), "address", "StreetAddress"},
+		{wordRegex(`zip(_?code)?|post_?code|postal`), "postal code", "truncate:3"},
+		{wordRegex(`birth(_?(day|date))?|dob`), "date of birth", "shift:30"},
+		{wordRegex(`ip(_?addr(ess)?)?`), "IP address", "IPv4"},
+	}
+
+	// camelCaseRegex finds the word boundaries of camelCased names.
+	camelCaseRegex = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+	emailRegex     = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[a-zA-Z]{2,}$`)
+	cardRegex      = regexp.MustCompile(`^\d[\d -]{11,21}\d$`)
+	ipAddressRegex = regexp.MustCompile(`^[0-9a-fA-F:.]{3,45}$`)
+	phoneRegex     = regexp.MustCompile(`^\+?[\d\s().-]{7,20}$`)
+
+	valueRules = []valueRule{
+		{"email", "EmailAddress", IsEmail},
+		{"card number", "mask:last=4", IsCardNumber},
+		{"IP address", "IPv4", isIPAddress},
+	}
+)
+
+// New creates a new scanner.
+func New(rdr reader.Reader, sampleSize uint64) *Scanner {
+	return &Scanner{reader: rdr, sampleSize: sampleSize}
+}
+
+// Scan samples all tables and returns the columns that look like personal data.
+func (s *Scanner) Scan() ([]*Finding, error) {
+	tables, err := s.reader.GetTables()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tables")
+	}
+
+	var findings []*Finding
+	for _, table := range tables {
+		tableFindings, err := s.ScanTable(table)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to scan table %s", table)
+		}
+
+		findings = append(findings, tableFindings...)
+	}
+
+	return findings, nil
+}
+
+// ScanTable samples a table and returns the columns that look like personal data.
+func (s *Scanner) ScanTable(table string) ([]*Finding, error) {
+	logger := log.WithField("table", table)
+	logger.Debug("scanning table")
+
+	columns, err := s.reader.GetColumns(table)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get columns")
+	}
+
+	samples, err := s.sample(table)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*Finding
+	for _, column := range columns {
+		if finding := DetectByName(table, column); finding != nil {
+			findings = append(findings, finding)
+			continue
+		}
+
+		if finding := DetectByValues(table, column, samples[column]); finding != nil {
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Column < findings[j].Column })
+
+	logger.WithField("findings", len(findings)).Debug("scanned table")
+
+	return findings, nil
+}
+
+// sample reads a number of rows from the table and returns the non empty text values by column.
+func (s *Scanner) sample(table string) (map[string][]string, error) {
+	rowChan := make(chan database.Row)
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- s.reader.ReadTable(table, rowChan, reader.ReadTableOpt{Limit: s.sampleSize}, nil)
+	}()
+
+	samples := make(map[string][]string)
+	for row := range rowChan {
+		for column, value := range row {
+			var str string
+			switch v := value.(type) {
+			case []byte:
+				str = string(v)
+			case string:
+				str = v
+			default:
+				continue
+			}
+
+			if str = strings.TrimSpace(str); str != "" {
+				samples[column] = append(samples[column], str)
+			}
+		}
+	}
+
+	if err := <-errChan; err != nil {
+		return nil, errors.Wrap(err, "failed to sample rows")
+	}
+
+	return samples, nil
+}
+
+// DetectByName flags a column by its name.
+func DetectByName(table string, column string) *Finding {
+	name := snakeCase(column)
+
+	for _, r := range nameRules {
+		if r.pattern.MatchString(name) {
+			return &Finding{Table: table, Column: column, Reason: "column name looks like " + r.kind, Rule: r.rule}
+		}
+	}
+
+	return nil
+}
+
+// wordRegex matches the alternatives as whole words of a snake_cased name.
+func wordRegex(alternatives string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|_)(` + alternatives + `)($|_)`)
+}
+
+// snakeCase lower cases a column name, separating its words with underscores.
+func snakeCase(name string) string {
+	name = camelCaseRegex.ReplaceAllString(name, "${1}_${2}")
+	name = strings.NewReplacer(" ", "_", "-", "_", ".", "_").Replace(name)
+
+	return strings.ToLower(name)
+}
+
+// IsPhoneColumn checks if the column name looks like it holds phone numbers.
+func IsPhoneColumn(column string) bool {
+	finding := DetectByName("", column)
+
+	return finding != nil && finding.Rule == phoneRule
+}
+
+// DetectByValues flags a column when most of the sampled values match a personal data pattern.
+func DetectByValues(table string, column string, values []string) *Finding {
+	if len(values) == 0 {
+		return nil
+	}
+
+	for _, r := range valueRules {
+		matches := 0
+		for _, value := range values {
+			if r.match(value) {
+				matches++
+			}
+		}
+
+		if float64(matches)/float64(len(values)) >= matchThreshold {
+			return &Finding{Table: table, Column: column, Reason: "values look like " + r.kind, Rule: r.rule}
+		}
+	}
+
+	return nil
+}
+
+// IsEmail checks if the value looks like an email address.
+func IsEmail(value string) bool {
+	return emailRegex.MatchString(value)
+}
+
+// IsCardNumber checks if the value is a Luhn valid card number.
+func IsCardNumber(value string) bool {
+	if !cardRegex.MatchString(value) {
+		return false
+	}
+
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(value)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// IsPhoneNumber checks if the value looks like a phone number.
+func IsPhoneNumber(value string) bool {
+	if !phoneRegex.MatchString(value) {
+		return false
+	}
+
+	digits := PhoneDigits(value)
+
+	return len(digits) >= 7 && len(digits) <= 15
+}
+
+// PhoneDigits returns the digits of a phone number, dropping its formatting.
+func PhoneDigits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+
+		return -1
+	}, value)
+}
+
+func isIPAddress(value string) bool {
+	return ipAddressRegex.MatchString(value) && net.ParseIP(value) != nil
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectByName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		column string
+		rule   string
+	}{
+		{column: "email", rule: "EmailAddress"},
+		{column: "contactEmail", rule: "EmailAddress"},
+		{column: "mobile_phone", rule: "Phone"},
+		{column: "iban", rule: "mask:last=4"},
+		{column: "ssn", rule: "mask:last=2"},
+		{column: "first_name", rule: "FirstName"},
+		{column: "surname", rule: "LastName"},
+		{column: "name", rule: "FullName"},
+		{column: "last_login_ip", rule: "IPv4"},
+		{column: "passport_no", rule: "mask:last=2"},
+		{column: "birthDate", rule: "shift:30"},
+		{column: "Billing Address", rule: "StreetAddress"},
+		{column: "product_name", rule: ""},
+		{column: "created_at", rule: ""},
+		{column: "classname", rule: ""},
+		{column: "syntax_id", rule: ""},
+		{column: "telemetry", rule: ""},
+		{column: "shipped", rule: ""},
+		{column: "last_login_at", rule: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.column, func(t *testing.T) {
+			finding := DetectByName("users", test.column)
+			if test.rule == "" {
+				assert.Nil(t, finding)
+				return
+			}
+
+			require.NotNil(t, finding)
+			assert.Equal(t, test.rule, finding.Rule)
+		})
+	}
+}
+
+func TestDetectByValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		values   []string
+		rule     string
+	}{
+		{scenario: "when values are emails", values: []string{"john@example.com", "jane@example.org", "n/a"}, rule: "EmailAddress"},
+		{scenario: "when values are card numbers", values: []string{"4111 1111 1111 1111", "5500-0000-0000-0004"}, rule: "mask:last=4"},
+		{scenario: "when values are ip addresses", values: []string{"10.0.0.1", "::1", "192.168.1.20"}, rule: "IPv4"},
+		{scenario: "when values are not personal data", values: []string{"pending", "shipped", "1234567890123"}, rule: ""},
+		{scenario: "when there are no values", values: nil, rule: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			finding := DetectByValues("orders", "data", test.values)
+			if test.rule == "" {
+				assert.Nil(t, finding)
+				return
+			}
+
+			require.NotNil(t, finding)
+			assert.Equal(t, test.rule, finding.Rule)
+		})
+	}
+}
+
+func TestIsCardNumber(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsCardNumber("4111111111111111"))
+	assert.True(t, IsCardNumber("4012 8888 8888 1881"))
+	assert.False(t, IsCardNumber("4111111111111112"))
+	assert.False(t, IsCardNumber("4111"))
+	assert.False(t, IsCardNumber("4111-1111-abcd-1111"))
+}
+
+func TestIsPhoneNumber(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsPhoneNumber("+49 30 1234567"))
+	assert.True(t, IsPhoneNumber("(555) 123-4567"))
+	assert.False(t, IsPhoneNumber("12345"))
+	assert.False(t, IsPhoneNumber("call me"))
+	assert.Equal(t, "15551234567", PhoneDigits("+1 (555) 123-4567"))
+}
@@ -0,0 +1,219 @@
+package verifier
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/anonymiser"
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	// Verifier compares a stolen database with the data selected from its source.
+	Verifier struct {
+		source reader.Reader
+		target reader.Reader
+		spec   *config.Spec
+	}
+
+	// Result is the verification of a table.
+	Result struct {
+		// Table is the table name.
+		Table string
+		// SourceRows is the number of rows selected from the source table.
+		SourceRows uint64
+		// TargetRows is the number of rows in the target table.
+		TargetRows uint64
+		// Checksummed is true when the content of the table was compared.
+		Checksummed bool
+		// SourceChecksum is the order independent checksum of the selected source rows.
+		SourceChecksum uint64
+		// TargetChecksum is the order independent checksum of the target rows.
+		TargetChecksum uint64
+		// Unverifiable explains why the target rows cannot be compared with the source rows, empty when they can.
+		Unverifiable string
+	}
+)
+
+// New creates a new verifier.
+func New(source reader.Reader, target reader.Reader, spec *config.Spec) *Verifier {
+	return &Verifier{source: source, target: target, spec: spec}
+}
+
+// Matches checks that the target table holds the rows selected from the source table,
+// unverifiable tables always match.
+func (r *Result) Matches() bool {
+	if r.Unverifiable != "" {
+		return true
+	}
+
+	return r.SourceRows == r.TargetRows && r.SourceChecksum == r.TargetChecksum
+}
+
+// Verify compares the row counts of all tables, and the content of the tables without anonymised columns.
+func (v *Verifier) Verify() ([]*Result, error) {
+	sourcePlanner, ok := v.source.(reader.Planner)
+	if !ok {
+		return nil, errors.New("source reader cannot count rows")
+	}
+
+	targetPlanner, ok := v.target.(reader.Planner)
+	if !ok {
+		return nil, errors.New("target reader cannot count rows")
+	}
+
+	tables, err := v.source.GetTables()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tables")
+	}
+
+	var results []*Result
+	for _, tableName := range tables {
+		logger := log.WithField("table", tableName)
+		logger.Debug("verifying table")
+
+		table, err := v.spec.Tables.FindByName(tableName)
+		if err != nil {
+			table = &config.Table{Name: tableName}
+		}
+
+		result := &Result{Table: tableName}
+		results = append(results, result)
+
+		// Transformed tables are written under their target name
+		transform := table.Transform
+		targetName := transform.TableName(tableName)
+		result.TargetRows, err = targetPlanner.CountRows(targetName, reader.ReadTableOpt{}, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to count target rows of %s", tableName)
+		}
+
+		// Only the structure of ignored tables is copied
+		if table.IgnoreData {
+			continue
+		}
+
+		opts := reader.NewReadTableOpt(table, v.spec)
+		result.SourceRows, err = sourcePlanner.CountRows(tableName, opts, v.spec.Matchers)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to count source rows of %s", tableName)
+		}
+
+		if result.Unverifiable = unverifiable(table); result.Unverifiable != "" {
+			continue
+		}
+
+		rules, err := anonymiser.TableRules(v.source, table, v.spec.Anonymise)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cast columns may hold the values in another representation
+		if anonymised(rules) || len(transform.Cast) > 0 || result.SourceRows != result.TargetRows {
+			continue
+		}
+
+		result.Checksummed = true
+		result.SourceChecksum, err = checksum(v.source, tableName, opts, v.spec.Matchers, transform.ColumnName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to checksum source rows of %s", tableName)
+		}
+
+		result.TargetChecksum, err = Checksum(v.target, targetName, reader.ReadTableOpt{}, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to checksum target rows of %s", tableName)
+		}
+	}
+
+	return results, nil
+}
+
+// unverifiable returns why the target table may legitimately hold other rows than the ones selected from the source,
+// empty when it only holds them.
+func unverifiable(table *config.Table) string {
+	if table.Incremental.Column != "" {
+		return "incremental table, the target keeps the rows of earlier steals"
+	}
+
+	switch table.WriteMode {
+	case config.WriteUpsert, config.WriteSkipExisting:
+		return fmt.Sprintf("%s write mode, the target keeps the rows of earlier steals", table.WriteMode)
+	}
+
+	return ""
+}
+
+// Checksum reads a table and returns a checksum of its rows that doesn't depend on their order.
+func Checksum(rdr reader.Reader, tableName string, opts reader.ReadTableOpt, matchers config.Matchers) (uint64, error) {
+	return checksum(rdr, tableName, opts, matchers, func(column string) string { return column })
+}
+
+// checksum reads a table and returns a checksum of its rows, with the columns renamed as they are on the target.
+func checksum(rdr reader.Reader, tableName string, opts reader.ReadTableOpt, matchers config.Matchers, columnName func(string) string) (uint64, error) {
+	rowChan := make(chan database.Row)
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- rdr.ReadTable(tableName, rowChan, opts, matchers)
+	}()
+
+	var sum uint64
+	for row := range rowChan {
+		// Sums are commutative, so the rows can be read in any order
+		sum += hashRow(row, columnName)
+	}
+
+	if err := <-errChan; err != nil {
+		return 0, err
+	}
+
+	return sum, nil
+}
+
+func hashRow(row database.Row, columnName func(string) string) uint64 {
+	renamed := make(map[string]interface{}, len(row))
+	columns := make([]string, 0, len(row))
+	for column, value := range row {
+		renamed[columnName(column)] = value
+		columns = append(columns, columnName(column))
+	}
+	sort.Strings(columns)
+
+	h := sha256.New()
+	for _, column := range columns {
+		fmt.Fprintf(h, "%s=%s\x00", column, formatValue(renamed[column]))
+	}
+
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// anonymised checks if any of the rules changes the column values.
+func anonymised(rules map[string]string) bool {
+	for _, rule := range rules {
+		if rule != anonymiser.KeepRule {
+			return true
+		}
+	}
+
+	return false
+}
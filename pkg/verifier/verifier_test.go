@@ -0,0 +1,151 @@
+package verifier
+
+import (
+	"testing"
+
+	"github.com/hellofresh/klepto/pkg/config"
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	rows := []database.Row{
+		{"id": int64(1), "name": []byte("john")},
+		{"id": int64(2), "name": nil},
+	}
+
+	tests := []struct {
+		scenario string
+		rows     []database.Row
+		matches  bool
+	}{
+		{
+			scenario: "when rows are the same",
+			rows:     rows,
+			matches:  true,
+		},
+		{
+			scenario: "when rows are in another order",
+			rows:     []database.Row{rows[1], rows[0]},
+			matches:  true,
+		},
+		{
+			scenario: "when a value is different",
+			rows:     []database.Row{rows[0], {"id": int64(2), "name": []byte("jane")}},
+		},
+		{
+			scenario: "when a row is missing",
+			rows:     rows[:1],
+		},
+	}
+
+	expected, err := Checksum(&mockReader{rows: rows}, "users", reader.ReadTableOpt{}, nil)
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			checksum, err := Checksum(&mockReader{rows: test.rows}, "users", reader.ReadTableOpt{}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, test.matches, expected == checksum)
+		})
+	}
+}
+
+type mockReader struct {
+	reader.Reader
+	rows []database.Row
+}
+
+func (m *mockReader) ReadTable(tableName string, rowChan chan<- database.Row, opts reader.ReadTableOpt, matchers config.Matchers) error {
+	defer close(rowChan)
+
+	for _, row := range m.rows {
+		rowChan <- row
+	}
+
+	return nil
+}
+
+func TestVerifyTransformedTable(t *testing.T) {
+	t.Parallel()
+
+	spec := &config.Spec{Tables: config.Tables{{
+		Name:      "tbl_user",
+		Transform: config.Transform{Rename: "users", Columns: map[string]string{"usr_name": "name"}},
+	}}}
+	source := &mockPlanner{
+		mockReader: mockReader{rows: []database.Row{{"id": int64(1), "usr_name": []byte("john")}}},
+		counts:     map[string]uint64{"tbl_user": 1},
+	}
+	target := &mockPlanner{
+		mockReader: mockReader{rows: []database.Row{{"id": int64(1), "name": []byte("john")}}},
+		counts:     map[string]uint64{"users": 1},
+	}
+
+	results, err := New(source, target, spec).Verify()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "tbl_user", results[0].Table)
+	assert.Equal(t, uint64(1), results[0].TargetRows)
+	assert.True(t, results[0].Checksummed)
+	assert.True(t, results[0].Matches())
+}
+
+func TestVerifyUnverifiableTables(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		table    *config.Table
+		skipped  bool
+	}{
+		{scenario: "when the table is appended", table: &config.Table{Name: "tbl_user"}},
+		{scenario: "when the table is truncated", table: &config.Table{Name: "tbl_user", WriteMode: config.WriteTruncate}},
+		{scenario: "when the table is upserted", table: &config.Table{Name: "tbl_user", WriteMode: config.WriteUpsert}, skipped: true},
+		{scenario: "when the existing rows are skipped", table: &config.Table{Name: "tbl_user", WriteMode: config.WriteSkipExisting}, skipped: true},
+		{scenario: "when the table is incremental", table: &config.Table{Name: "tbl_user", Incremental: config.Incremental{Column: "updated_at"}}, skipped: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			spec := &config.Spec{Tables: config.Tables{test.table}}
+			source := &mockPlanner{
+				mockReader: mockReader{rows: []database.Row{{"id": int64(2)}}},
+				counts:     map[string]uint64{"tbl_user": 1},
+			}
+			target := &mockPlanner{
+				mockReader: mockReader{rows: []database.Row{{"id": int64(1)}, {"id": int64(2)}}},
+				counts:     map[string]uint64{"tbl_user": 2},
+			}
+
+			results, err := New(source, target, spec).Verify()
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, test.skipped, results[0].Unverifiable != "")
+			assert.Equal(t, test.skipped, results[0].Matches())
+		})
+	}
+}
+
+type mockPlanner struct {
+	mockReader
+	counts map[string]uint64
+}
+
+func (m *mockPlanner) GetTables() ([]string, error) { return []string{"tbl_user"}, nil }
+
+func (m *mockPlanner) BuildQuery(string, reader.ReadTableOpt, config.Matchers) (string, []interface{}, error) {
+	return "", nil, nil
+}
+
+func (m *mockPlanner) CountRows(tableName string, opts reader.ReadTableOpt, matchers config.Matchers) (uint64, error) {
+	return m.counts[tableName], nil
+}
+
+func (m *mockPlanner) EstimateRows(tableName string, opts reader.ReadTableOpt, matchers config.Matchers) (uint64, error) {
+	return m.counts[tableName], nil
+}